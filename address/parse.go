@@ -0,0 +1,276 @@
+package address
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// repetitionIndexes are the repetition indexes following a house number
+var repetitionIndexes = map[string]bool{
+	"bis": true, "ter": true, "quater": true,
+}
+
+// complementAbbreviations maps the words introducing an address complement to
+// their full forms
+var complementAbbreviations = map[string]string{
+	"BAT":         "bâtiment",
+	"BÂT":         "bâtiment",
+	"BATIMENT":    "bâtiment",
+	"BÂTIMENT":    "bâtiment",
+	"APP":         "appartement",
+	"APPT":        "appartement",
+	"APT":         "appartement",
+	"APPARTEMENT": "appartement",
+	"RES":         "résidence",
+	"RÉS":         "résidence",
+	"RESIDENCE":   "résidence",
+	"RÉSIDENCE":   "résidence",
+	"LD":          "lieu-dit",
+	"LIEU-DIT":    "lieu-dit",
+	"LIEUDIT":     "lieu-dit",
+	"ESC":         "escalier",
+	"ESCALIER":    "escalier",
+	"IMM":         "immeuble",
+	"IMMEUBLE":    "immeuble",
+}
+
+// keyAbbreviations are expanded in comparison keys, so that "St-Michel" and
+// "Saint-Michel" share a key
+var keyAbbreviations = map[string]string{
+	"st":  "saint",
+	"ste": "sainte",
+}
+
+// Street is a French street address split into its parts. The parts are
+// formatted as by FormatLine: "7 bis rue de l'Eglise, bât. B" gives the
+// number "7", the repetition index "bis", the type "rue", the name
+// "de l'Eglise" and the complement "Bâtiment B".
+type Street struct {
+	Number     string // House number, e.g. "7"
+	Repetition string // Repetition index: "bis", "ter" or "quater"
+	Type       string // Street type in full, e.g. "avenue"
+	Name       string // Street name, e.g. "des Lilas"
+	Complement string // Building, apartment, residence or locality, comma separated
+}
+
+// Parse splits a street address of one or more lines, separated by newlines
+// or commas, into its parts. The first line with a house number or a street
+// type is the street, lines introduced by a complement word (bâtiment,
+// appartement, résidence, lieu-dit...) and the other lines are complements.
+// Without such a line, the first line not being a complement is the street
+// name, e.g. a locality.
+func Parse(street string) Street {
+	var s Street
+	var complements, others []string
+	found := false
+	segments := strings.FieldsFunc(street, func(r rune) bool { return r == '\n' || r == ',' })
+	for i, segment := range segments {
+		tokens := strings.Fields(segment)
+		if i+1 < len(segments) && isHouseNumber(tokens) {
+			segments[i+1] = segment + " " + segments[i+1] // "3, rue des Lilas"
+			continue
+		}
+		switch {
+		case len(tokens) == 0:
+		case complementWord(tokens) != "":
+			complements = append(complements, formatComplement(tokens))
+		case !found && (startsWithNumber(tokens[0]) || isStreetType(tokens[0])):
+			found = true
+			if complement := s.parseLine(tokens); complement != "" {
+				complements = append(complements, complement)
+			}
+		default:
+			others = append(others, segment)
+		}
+	}
+
+	if !found && len(others) > 0 {
+		s.Name = formatWords(strings.Fields(others[0]))
+		others = others[1:]
+	}
+	for _, other := range others {
+		complements = append(complements, capitalizeFirstLetter(formatWords(strings.Fields(other))))
+	}
+	s.Complement = strings.Join(complements, ", ")
+	return s
+}
+
+// parseLine reads the number, repetition index, type and name of a street
+// line, and returns the complement ending it, if any: "3 rue des Lilas bât. B".
+func (s *Street) parseLine(tokens []string) string {
+	if n := len(tokens[0]) - len(strings.TrimLeft(tokens[0], "0123456789")); n > 0 {
+		s.Number = tokens[0][:n]
+		if rest := strings.ToLower(tokens[0][n:]); repetitionIndexes[rest] {
+			s.Repetition = rest // Glued: "7bis"
+		} else if rest != "" {
+			s.Number = tokens[0] // Kept whole: "7-9"
+		}
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && s.Repetition == "" {
+		if r := strings.ToLower(removeTrailingPunctuation(tokens[0])); repetitionIndexes[r] {
+			s.Repetition = r
+			tokens = tokens[1:]
+		}
+	}
+	if len(tokens) > 0 {
+		if t, ok := streetType(tokens[0]); ok {
+			s.Type = t
+			tokens = tokens[1:]
+		}
+	}
+
+	// A complement word ends the name, unless it is part of it: "rue de la Résidence"
+	for i := 1; i < len(tokens); i++ {
+		if complementWord(tokens[i:]) != "" && !frenchSmallWords[strings.ToLower(tokens[i-1])] {
+			s.Name = formatWords(tokens[:i])
+			return formatComplement(tokens[i:])
+		}
+	}
+	s.Name = formatWords(tokens)
+	return ""
+}
+
+// Line returns the street line: number, repetition index, type and name.
+func (s Street) Line() string {
+	var parts []string
+	for _, part := range []string{s.Number, s.Repetition, s.Type, s.Name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	line := strings.Join(parts, " ")
+	if s.Number == "" {
+		line = capitalizeFirstLetter(line)
+	}
+	return line
+}
+
+// String returns the street line, then the complements on a second line.
+func (s Street) String() string {
+	line := s.Line()
+	if s.Complement == "" {
+		return line
+	}
+	if line == "" {
+		return s.Complement
+	}
+	return line + "\n" + s.Complement
+}
+
+// IsZero reports whether the street is empty.
+func (s Street) IsZero() bool {
+	return s == Street{}
+}
+
+// Key returns the comparison key of the street line, complement excluded:
+// lowercase, without diacritics, punctuation turned into spaces and "st"
+// expanded, so that "2 ter bd St-Michel" and "2 TER BOULEVARD SAINT MICHEL"
+// share a key. The key is empty without a street name.
+func (s Street) Key() string {
+	if s.Name == "" {
+		return ""
+	}
+	line := foldDiacritics(strings.ToLower(s.Line()))
+	words := strings.FieldsFunc(line, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	for i, w := range words {
+		if full, ok := keyAbbreviations[w]; ok {
+			words[i] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Helper functions
+
+// streetType returns the full form of a street type, e.g. "avenue" for "AV."
+func streetType(token string) (string, bool) {
+	if fullForm, exists := streetAbbreviations[removeTrailingPunctuation(strings.ToUpper(token))]; exists {
+		return fullForm, true
+	}
+	lowToken := strings.ToLower(token)
+	return lowToken, streetTypes[lowToken]
+}
+
+// isStreetType checks if a token is a street type or its abbreviation
+func isStreetType(token string) bool {
+	_, ok := streetType(token)
+	return ok
+}
+
+// startsWithNumber checks if a token starts with a digit
+func startsWithNumber(token string) bool {
+	return token != "" && token[0] >= '0' && token[0] <= '9'
+}
+
+// isHouseNumber checks if tokens are only a house number and its repetition
+// index
+func isHouseNumber(tokens []string) bool {
+	switch len(tokens) {
+	case 1:
+		return startsWithNumber(tokens[0])
+	case 2:
+		return startsWithNumber(tokens[0]) && repetitionIndexes[strings.ToLower(removeTrailingPunctuation(tokens[1]))]
+	}
+	return false
+}
+
+// complementWord returns the full form of the complement word starting the
+// tokens, or an empty string
+func complementWord(tokens []string) string {
+	word := removeTrailingPunctuation(strings.ToUpper(tokens[0]))
+	if word == "LIEU" && len(tokens) > 1 && removeTrailingPunctuation(strings.ToUpper(tokens[1])) == "DIT" {
+		return "lieu-dit"
+	}
+	return complementAbbreviations[word]
+}
+
+// formatComplement formats complements starting with a complement word:
+// "bât. b appt 12" -> "Bâtiment B, Appartement 12"
+func formatComplement(tokens []string) string {
+	word := complementWord(tokens)
+	if word == "lieu-dit" && strings.EqualFold(tokens[0], "lieu") {
+		tokens = tokens[1:] // "lieu dit"
+	}
+	tokens = tokens[1:]
+	for i := 1; i < len(tokens); i++ {
+		if complementWord(tokens[i:]) != "" && !frenchSmallWords[strings.ToLower(tokens[i-1])] {
+			return formatComplement(append([]string{word}, tokens[:i]...)) + ", " + formatComplement(tokens[i:])
+		}
+	}
+	if len(tokens) == 0 {
+		return capitalizeFirstLetter(word)
+	}
+	return capitalizeFirstLetter(word) + " " + formatWords(tokens)
+}
+
+// formatWords capitalizes words following a first one: small words and street
+// types remain lowercase
+func formatWords(tokens []string) string {
+	formatted := make([]string, len(tokens))
+	for i, token := range tokens {
+		token = strings.ToLower(token)
+		if isNumber(token) {
+			formatted[i] = token
+			continue
+		}
+		formatted[i] = capitalizeToken(token, false)
+	}
+	return strings.Join(formatted, " ")
+}
+
+// foldDiacritics removes accents: "é" -> "e"
+func foldDiacritics(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return folded
+}
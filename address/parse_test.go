@@ -0,0 +1,75 @@
+package address
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Street
+	}{
+		{"31 AV DES KORRIGANS", Street{Number: "31", Type: "avenue", Name: "des Korrigans"}},
+		{"7 bis rue de l'eglise", Street{Number: "7", Repetition: "bis", Type: "rue", Name: "de l'Eglise"}},
+		{"2TER BD ST-MICHEL", Street{Number: "2", Repetition: "ter", Type: "boulevard", Name: "St-Michel"}},
+		{"12 quater, place du Marché", Street{Number: "12", Repetition: "quater", Type: "place", Name: "du Marché"}},
+		{"5 Rue des Lilas bât. B appt 12", Street{Number: "5", Type: "rue", Name: "des Lilas", Complement: "Bâtiment B, Appartement 12"}},
+		{"Résidence les Pins\n3 allée des Tilleuls", Street{Number: "3", Type: "allée", Name: "des Tilleuls", Complement: "Résidence les Pins"}},
+		{"10 rue de la Résidence", Street{Number: "10", Type: "rue", Name: "de la Résidence"}},
+		{"8 chemin des Vignes\nlieu dit la croix", Street{Number: "8", Type: "chemin", Name: "des Vignes", Complement: "Lieu-dit la Croix"}},
+		{"3, rue des Lilas", Street{Number: "3", Type: "rue", Name: "des Lilas"}},
+		{"LE BOURG", Street{Name: "le Bourg"}},
+		{"Bâtiment C, Le Moulin", Street{Name: "le Moulin", Complement: "Bâtiment C"}},
+		{"", Street{}},
+	}
+
+	for _, c := range cases {
+		if got := Parse(c.in); got != c.want {
+			t.Fatalf("Parse(%q) = %+v; want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestStreetString(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"Bât. B\n3 RUE DES LILAS", "3 rue des Lilas\nBâtiment B"},
+		{"7 bis rue de l'eglise", "7 bis rue de l'Eglise"},
+		{"rue de la paix", "Rue de la Paix"},
+		{"LE BOURG", "Le Bourg"},
+		{"appt 4", "Appartement 4"},
+	}
+
+	for _, c := range cases {
+		if got := Parse(c.in).String(); got != c.want {
+			t.Fatalf("Parse(%q).String() = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestStreetKey(t *testing.T) {
+	same := [][2]string{
+		{"2 ter bd St-Michel", "2 TER BOULEVARD SAINT MICHEL"},
+		{"3 rue des Lilas\nBâtiment B", "3 RUE DES LILAS"},
+		{"19 cour de l'École", "19 COUR DE L ECOLE"},
+	}
+	for _, s := range same {
+		if k1, k2 := Parse(s[0]).Key(), Parse(s[1]).Key(); k1 != k2 {
+			t.Errorf("Key() = %q and %q; want the same for %q and %q", k1, k2, s[0], s[1])
+		}
+	}
+
+	different := [][2]string{
+		{"3 rue des Lilas", "3 bis rue des Lilas"},
+		{"3 rue des Lilas", "5 rue des Lilas"},
+	}
+	for _, d := range different {
+		if Parse(d[0]).Key() == Parse(d[1]).Key() {
+			t.Errorf("Key() equal for %q and %q", d[0], d[1])
+		}
+	}
+
+	if got := Parse("Appartement 4").Key(); got != "" {
+		t.Errorf("Key() = %q; want empty without a street name", got)
+	}
+}
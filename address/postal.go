@@ -0,0 +1,356 @@
+package address
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Problem is the reason a postal code and city are invalid.
+type Problem string
+
+const (
+	ProblemMissingZipCode Problem = "missing postal code"
+	ProblemMissingCity    Problem = "missing city"
+	ProblemZipCodeFormat  Problem = "invalid postal code"
+	ProblemUnknownZipCode Problem = "unknown postal code"
+	ProblemCityMismatch   Problem = "city not matching the postal code"
+)
+
+// citySmallWords are the words of city names that remain lowercase, besides
+// frenchSmallWords
+var citySmallWords = map[string]bool{
+	"en": true, "sous": true, "lès": true,
+}
+
+// Commune is a line of a postal code dataset.
+type Commune struct {
+	ZipCode string
+	Name    string // Name of the commune
+	Label   string // Delivery label, the city written on mail
+	Line5   string // Locality or former commune served, if any
+}
+
+// PostalCodes is a dataset of the French postal codes and the communes they
+// serve, such as the La Poste "base officielle des codes postaux", used to
+// validate addresses offline.
+type PostalCodes struct {
+	byZipCode map[string][]Commune
+	byCity    map[string][]Commune // By city key, see cityKey
+}
+
+// LoadPostalCodes reads a postal code dataset: a CSV file separated by
+// semicolons or commas, with a header naming the columns as in the La Poste
+// file: Code_postal, Nom_de_la_commune, Libellé_d_acheminement and Ligne_5.
+// The postal code and one of the name and label columns are required.
+func LoadPostalCodes(r io.Reader) (*PostalCodes, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(1024)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error reading postal codes: %v", err)
+	}
+	reader := csv.NewReader(br)
+	if line, _, _ := bytes.Cut(header, []byte("\n")); bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading postal codes header: %v", err)
+	}
+	zipCol, nameCol, labelCol, line5Col := -1, -1, -1, -1
+	for i, h := range headers {
+		switch h = columnName(h); {
+		case h == "code_postal":
+			zipCol = i
+		case h == "nom_de_la_commune" || h == "nom_commune":
+			nameCol = i
+		case strings.Contains(h, "acheminement"):
+			labelCol = i
+		case h == "ligne_5":
+			line5Col = i
+		}
+	}
+	if zipCol < 0 || (nameCol < 0 && labelCol < 0) {
+		return nil, fmt.Errorf("error reading postal codes: want Code_postal and Nom_de_la_commune or Libellé_d_acheminement columns, got %v", headers)
+	}
+
+	p := &PostalCodes{byZipCode: make(map[string][]Commune), byCity: make(map[string][]Commune)}
+	column := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading postal codes: %v", err)
+		}
+		c := Commune{
+			ZipCode: normalizeZipCode(column(record, zipCol)),
+			Name:    column(record, nameCol),
+			Label:   column(record, labelCol),
+			Line5:   column(record, line5Col),
+		}
+		if !isZipCode(c.ZipCode) || len(c.cities()) == 0 {
+			continue
+		}
+		p.byZipCode[c.ZipCode] = append(p.byZipCode[c.ZipCode], c)
+		for _, city := range c.cities() {
+			key := cityKey(city)
+			if !containsZipCode(p.byCity[key], c.ZipCode) {
+				p.byCity[key] = append(p.byCity[key], c)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Check is the result of the validation of a postal code and city.
+type Check struct {
+	Problem Problem // Empty when valid
+	ZipCode string  // Padded postal code when valid, else suggested postal code, if any
+	City    string  // Normalized city when valid, else suggested city, if any
+}
+
+// Valid reports whether the postal code and city are valid.
+func (c Check) Valid() bool {
+	return c.Problem == ""
+}
+
+// Check validates a postal code and city. The city of a valid address is
+// normalized to the spelling of the dataset, in French case when the dataset
+// is in capitals, unless it only differs by its case, accents and punctuation
+// and is already capitalized: "Saint-Étienne" is kept for "SAINT ETIENNE",
+// "PARIS" becomes "Paris". A postal code missing its leading zero, "1000", is
+// padded to "01000". An invalid address comes with the corrections
+// found: a typo in the postal code ("75O12"), the city closest to those of
+// the postal code, or the postal code of the city.
+func (p *PostalCodes) Check(zipCode, city string) Check {
+	zipCode = strings.Join(strings.Fields(zipCode), "")
+	city = strings.Join(strings.Fields(city), " ")
+	if padded := normalizeZipCode(zipCode); padded != zipCode && len(p.byZipCode[padded]) > 0 {
+		// The leading zero dropped by a spreadsheet
+		check := p.Check(padded, city)
+		if check.Valid() {
+			check.ZipCode = padded
+		}
+		return check
+	}
+	if zipCode == "" {
+		if city == "" {
+			return Check{}
+		}
+		return p.suggestZipCode(ProblemMissingZipCode, city)
+	}
+
+	problem := Problem("")
+	if !isZipCode(zipCode) {
+		problem = ProblemZipCodeFormat
+		zipCode = fixZipCode(zipCode)
+	}
+	communes := p.byZipCode[zipCode]
+	if len(communes) == 0 {
+		if problem == "" {
+			problem = ProblemUnknownZipCode
+		}
+		return p.suggestZipCode(problem, city)
+	}
+	if problem != "" {
+		// The fixed postal code is the suggestion
+		check := p.Check(zipCode, city)
+		check.Problem, check.ZipCode = problem, zipCode
+		return check
+	}
+
+	if city == "" {
+		return Check{Problem: ProblemMissingCity, City: uniqueLabel(communes)}
+	}
+	for _, c := range communes {
+		for _, name := range c.cities() {
+			if cityKey(name) == cityKey(city) {
+				return Check{City: spelling(city, name)}
+			}
+		}
+	}
+
+	// The city closest to the given one, else the postal code of the city,
+	// else the single city of the postal code
+	check := Check{Problem: ProblemCityMismatch}
+	best := 3
+	for _, c := range communes {
+		for _, name := range c.cities() {
+			if d := levenshteinDistance(cityKey(name), cityKey(city)); d < best {
+				best, check.City = d, spelling(city, name)
+			}
+		}
+	}
+	if check.City == "" {
+		if suggestion := p.suggestZipCode(ProblemCityMismatch, city); suggestion.ZipCode != "" {
+			return suggestion
+		}
+		check.City = uniqueLabel(communes)
+	}
+	return check
+}
+
+// suggestZipCode returns an invalid check with the postal code of the city,
+// when it has a single one.
+func (p *PostalCodes) suggestZipCode(problem Problem, city string) Check {
+	check := Check{Problem: problem}
+	if communes := p.byCity[cityKey(city)]; len(communes) == 1 {
+		check.ZipCode = communes[0].ZipCode
+		for _, name := range communes[0].cities() {
+			if cityKey(name) == cityKey(city) {
+				check.City = spelling(city, name)
+			}
+		}
+	}
+	return check
+}
+
+// cities returns the names under which a commune is known.
+func (c Commune) cities() []string {
+	var cities []string
+	for _, name := range []string{c.Label, c.Name, c.Line5} {
+		if name != "" {
+			cities = append(cities, name)
+		}
+	}
+	return cities
+}
+
+// Helper functions
+
+// uniqueLabel returns the city of communes sharing a postal code, when they
+// have a single one
+func uniqueLabel(communes []Commune) string {
+	label := communes[0].cities()[0]
+	for _, c := range communes[1:] {
+		if cityKey(c.cities()[0]) != cityKey(label) {
+			return ""
+		}
+	}
+	return spelling("", label)
+}
+
+// spelling returns the normalized spelling of a city matching a name of the
+// dataset (see PostalCodes.Check)
+func spelling(city, name string) string {
+	if isCapitalized(city) && cityKey(city) == cityKey(name) {
+		return city
+	}
+	if strings.ToUpper(name) != name {
+		return name
+	}
+	return formatCity(name)
+}
+
+// isCapitalized checks if a name has both uppercase and lowercase letters
+func isCapitalized(s string) bool {
+	return strings.IndexFunc(s, unicode.IsUpper) >= 0 && strings.IndexFunc(s, unicode.IsLower) >= 0
+}
+
+// formatCity capitalizes a city name in capitals: "BOURG EN BRESSE" ->
+// "Bourg en Bresse"
+func formatCity(name string) string {
+	tokens := strings.Fields(strings.ToLower(name))
+	for i, token := range tokens {
+		if i > 0 && (frenchSmallWords[token] || citySmallWords[token]) {
+			continue
+		}
+		tokens[i] = capitalizeWithSpecialChars(token)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// cityKey returns the comparison key of a city: lowercase, without
+// diacritics, punctuation and arrondissement, "st" expanded. "PARIS 11" and
+// "Paris" share the key "paris", "St-Étienne" and "SAINT ETIENNE" share
+// "saint etienne".
+func cityKey(city string) string {
+	words := strings.FieldsFunc(foldDiacritics(strings.ToLower(city)), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	var kept []string
+	for _, w := range words {
+		if full, ok := keyAbbreviations[w]; ok {
+			w = full
+		}
+		if w == "cedex" || startsWithNumber(w) {
+			continue
+		}
+		kept = append(kept, w)
+	}
+	return strings.Join(kept, " ")
+}
+
+// columnName normalizes a header of the dataset: "Libellé_d_acheminement" ->
+// "libelle_d_acheminement"
+func columnName(header string) string {
+	header = foldDiacritics(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff"))))
+	header = strings.TrimPrefix(header, "#")
+	return strings.Join(strings.FieldsFunc(header, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_")
+}
+
+// isZipCode checks if a postal code has 5 digits
+func isZipCode(zipCode string) bool {
+	return len(zipCode) == 5 && isNumber(zipCode) && zipCode[0] != '+' && zipCode[0] != '-'
+}
+
+// normalizeZipCode restores the leading zero a spreadsheet drops: "1000" ->
+// "01000"
+func normalizeZipCode(zipCode string) string {
+	if len(zipCode) == 4 && isNumber(zipCode) {
+		return "0" + zipCode
+	}
+	return zipCode
+}
+
+// fixZipCode replaces the letters typed for digits: "75O12" -> "75012"
+func fixZipCode(zipCode string) string {
+	return normalizeZipCode(strings.NewReplacer("O", "0", "o", "0", "I", "1", "l", "1").Replace(zipCode))
+}
+
+// containsZipCode checks if one of the communes has the postal code
+func containsZipCode(communes []Commune, zipCode string) bool {
+	for _, c := range communes {
+		if c.ZipCode == zipCode {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshteinDistance counts the edits between two strings, on runes
+func levenshteinDistance(s1, s2 string) int {
+	r1, r2 := []rune(s1), []rune(s2)
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(r2)]
+}
@@ -0,0 +1,75 @@
+package address
+
+import (
+	"strings"
+	"testing"
+)
+
+// laPoste is an extract of the La Poste postal code dataset.
+const laPoste = `#Code_commune_INSEE;Nom_de_la_commune;Code_postal;Libellé_d_acheminement;Ligne_5
+75111;PARIS 11;75011;PARIS;
+75112;PARIS 12;75012;PARIS;
+42218;ST ETIENNE;42000;ST ETIENNE;
+01053;BOURG EN BRESSE;1000;BOURG EN BRESSE;
+01053;BOURG EN BRESSE;01000;BOURG EN BRESSE;BROU
+69123;LYON 03;69003;LYON;
+78297;JAMBVILLE;78440;JAMBVILLE;
+78290;ISSOU;78440;ISSOU;
+`
+
+func TestLoadPostalCodes(t *testing.T) {
+	p, err := LoadPostalCodes(strings.NewReader(laPoste))
+	if err != nil {
+		t.Fatalf("LoadPostalCodes() error = %v", err)
+	}
+	if got := len(p.byZipCode["01000"]); got != 2 {
+		t.Errorf("LoadPostalCodes() = %d communes for 01000, want 2 with the leading zero restored", got)
+	}
+
+	comma := "code_postal,nom_commune\n75011,Paris\n"
+	if p, err := LoadPostalCodes(strings.NewReader(comma)); err != nil || len(p.byZipCode["75011"]) != 1 {
+		t.Errorf("LoadPostalCodes(%q) = %+v, %v", comma, p, err)
+	}
+
+	if _, err := LoadPostalCodes(strings.NewReader("zip;town\n75011;Paris\n")); err == nil {
+		t.Error("LoadPostalCodes() without the expected columns, want error")
+	}
+}
+
+func TestPostalCodesCheck(t *testing.T) {
+	p, err := LoadPostalCodes(strings.NewReader(laPoste))
+	if err != nil {
+		t.Fatalf("LoadPostalCodes() error = %v", err)
+	}
+
+	cases := []struct {
+		zipCode, city string
+		want          Check
+	}{
+		{"75011", "Paris", Check{City: "Paris"}},
+		{"75011", "PARIS", Check{City: "Paris"}},
+		{"75 011", "paris 11", Check{City: "Paris"}},
+		{"42000", "Saint-Étienne", Check{City: "Saint-Étienne"}},
+		{"01000", "BOURG-EN-BRESSE", Check{City: "Bourg en Bresse"}},
+		{"01000", "Brou", Check{City: "Brou"}},
+		{"1000", "Bourg-en-Bresse", Check{ZipCode: "01000", City: "Bourg-en-Bresse"}},
+		{"1000", "Lyon", Check{Problem: ProblemCityMismatch, ZipCode: "69003", City: "Lyon"}},
+		{"", "", Check{}},
+		{"75O12", "Paris", Check{Problem: ProblemZipCodeFormat, ZipCode: "75012", City: "Paris"}},
+		{"7501", "Paris", Check{Problem: ProblemZipCodeFormat}},
+		{"75099", "Paris", Check{Problem: ProblemUnknownZipCode}},
+		{"75099", "Jambville", Check{Problem: ProblemUnknownZipCode, ZipCode: "78440", City: "Jambville"}},
+		{"78440", "Jamville", Check{Problem: ProblemCityMismatch, City: "Jambville"}},
+		{"69003", "Saint Etienne", Check{Problem: ProblemCityMismatch, ZipCode: "42000", City: "Saint Etienne"}},
+		{"69003", "Marseille", Check{Problem: ProblemCityMismatch, City: "Lyon"}},
+		{"78440", "Marseille", Check{Problem: ProblemCityMismatch}},
+		{"", "Issou", Check{Problem: ProblemMissingZipCode, ZipCode: "78440", City: "Issou"}},
+		{"69003", "", Check{Problem: ProblemMissingCity, City: "Lyon"}},
+	}
+
+	for _, c := range cases {
+		if got := p.Check(c.zipCode, c.city); got != c.want {
+			t.Errorf("Check(%q, %q) = %+v; want %+v", c.zipCode, c.city, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package contact
+
+import (
+	"strings"
+
+	"github.com/tinque/totem/address"
+)
+
+type AddressType string
+
+const (
+	AddressHome  AddressType = "home"
+	AddressWork  AddressType = "work"
+	AddressOther AddressType = "other"
+)
+
+// AddressTypes lists the address types in display order.
+var AddressTypes = []AddressType{AddressHome, AddressWork, AddressOther}
+
+// Address is a postal address. Addresses are merged as a whole, a street
+// never being mixed with the city of another address.
+type Address struct {
+	Street  string `json:"street,omitempty"` // Lines separated by \n
+	ZipCode string `json:"zip_code,omitempty"`
+	City    string `json:"city,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+// IsZero reports whether the address is empty.
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// String returns the address on a single line, the street being parsed so
+// that its complement follows the street line whatever their order:
+// "3 rue des Lilas, Bâtiment B, 75011 Paris, France".
+func (a Address) String() string {
+	var parts []string
+	for _, line := range strings.Split(address.Parse(a.Street).String(), "\n") {
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+	if place := strings.TrimSpace(a.ZipCode + " " + a.City); place != "" {
+		parts = append(parts, place)
+	}
+	if a.Country != "" {
+		parts = append(parts, a.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c *Contact) GetAddress(at AddressType) Address {
+	if c.Addresses == nil {
+		return Address{}
+	}
+	return c.Addresses[at]
+}
+
+func (c *Contact) SetAddress(at AddressType, a Address) {
+	if c.Addresses == nil {
+		c.Addresses = make(map[AddressType]Address)
+	}
+	c.Addresses[at] = a
+}
+
+// MailingAddress returns the address to send letters to: the home address,
+// else the other one. Work addresses are left out.
+func (c *Contact) MailingAddress() Address {
+	if a := c.GetAddress(AddressHome); !a.IsZero() {
+		return a
+	}
+	return c.GetAddress(AddressOther)
+}
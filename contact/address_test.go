@@ -0,0 +1,60 @@
+package contact
+
+import (
+	"reflect"
+	"testing"
+)
+
+// homeAddress returns the addresses of a contact living at a.
+func homeAddress(a Address) map[AddressType]Address {
+	return map[AddressType]Address{AddressHome: a}
+}
+
+func TestAddressString(t *testing.T) {
+	tests := []struct {
+		name    string
+		address Address
+		want    string
+	}{
+		{"Full", Address{Street: "3 rue des Lilas\nBâtiment B", ZipCode: "75011", City: "Paris", Country: "France"}, "3 rue des Lilas, Bâtiment B, 75011 Paris, France"},
+		{"Complement first", Address{Street: "Bât. B\n3 RUE DES LILAS", City: "Paris"}, "3 rue des Lilas, Bâtiment B, Paris"},
+		{"City only", Address{City: "Paris"}, "Paris"},
+		{"Empty", Address{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.address.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMailingAddress(t *testing.T) {
+	work := Address{Street: "1 place de la Bourse", City: "Paris"}
+	other := Address{Street: "8 avenue Foch", City: "Lyon"}
+	c := Contact{Addresses: map[AddressType]Address{AddressWork: work}}
+	if got := c.MailingAddress(); !got.IsZero() {
+		t.Errorf("MailingAddress() = %+v, want none", got)
+	}
+	c.SetAddress(AddressOther, other)
+	if got := c.MailingAddress(); got != other {
+		t.Errorf("MailingAddress() = %+v, want %+v", got, other)
+	}
+}
+
+func TestMergeContactAddresses(t *testing.T) {
+	home := Address{Street: "3 rue des Lilas", ZipCode: "75011", City: "Paris"}
+	destination := Contact{Addresses: homeAddress(home)}
+	source := Contact{Addresses: map[AddressType]Address{
+		AddressHome: {ZipCode: "69006", City: "Lyon"},
+		AddressWork: {Street: "1 place de la Bourse", City: "Paris"},
+	}}
+	destination.MergeContact(&source)
+
+	// The home address is kept whole, not completed by the other one
+	want := map[AddressType]Address{AddressHome: home, AddressWork: source.Addresses[AddressWork]}
+	if !reflect.DeepEqual(destination.Addresses, want) {
+		t.Errorf("Addresses = %+v, want %+v", destination.Addresses, want)
+	}
+}
@@ -0,0 +1,97 @@
+package contact
+
+import (
+	"slices"
+)
+
+// lastNamePrefixLength is the number of runes of the normalized last name used
+// as a blocking key, catching typos the phonetic key does not absorb.
+const lastNamePrefixLength = 3
+
+// prefix returns the first n runes of s.
+func prefix(s string, n int) string {
+	r := []rune(s)
+	return string(r[:min(len(r), n)])
+}
+
+// blockingKeys returns the keys under which a contact is indexed: ID, member code,
+// normalized emails and phones, and two name keys combining the last name
+// (phonetic key, or first runes) with the initial of the first name.
+// Only contacts sharing at least one key are compared for duplication.
+func blockingKeys(c *Contact, p contactProfile) []string {
+	var keys []string
+
+	if c.ID != "" {
+		keys = append(keys, "id:"+c.ID)
+	}
+
+	if c.MemberCode != "" {
+		keys = append(keys, "member:"+c.MemberCode)
+	}
+
+	for _, email := range p.emails {
+		keys = append(keys, "email:"+email)
+	}
+
+	for _, phone := range c.Phones {
+		if phone = NormalizePhone(phone); phone != "" {
+			keys = append(keys, "phone:"+phone)
+		}
+	}
+
+	if p.lastPhonetic != "" {
+		keys = append(keys, "phonetic:"+p.lastPhonetic+"|"+prefix(p.firstPhonetic, 1))
+	}
+
+	if p.last != "" {
+		keys = append(keys, "name:"+prefix(p.last, lastNamePrefixLength)+"|"+prefix(p.first, 1))
+	}
+
+	return keys
+}
+
+// blockIndex groups contacts by blocking key, so that duplicate detection
+// only compares candidate pairs instead of every pair of contacts.
+type blockIndex struct {
+	profiles []contactProfile
+	keys     [][]string       // Blocking keys of each contact
+	blocks   map[string][]int // Contact indices by key, in ascending order
+	seen     []int            // Last contact for which an index was collected
+}
+
+func newBlockIndex(contacts []Contact) *blockIndex {
+	bi := &blockIndex{
+		profiles: make([]contactProfile, len(contacts)),
+		keys:     make([][]string, len(contacts)),
+		blocks:   make(map[string][]int),
+		seen:     make([]int, len(contacts)),
+	}
+	names := newNameCache()
+	for i := range contacts {
+		bi.profiles[i] = names.contactProfile(&contacts[i])
+		bi.keys[i] = blockingKeys(&contacts[i], bi.profiles[i])
+		for _, k := range bi.keys[i] {
+			bi.blocks[k] = append(bi.blocks[k], i)
+		}
+	}
+	return bi
+}
+
+// candidates returns, in ascending order, the indices greater than i of the
+// contacts sharing at least one block with contact i.
+func (bi *blockIndex) candidates(i int) []int {
+	var result []int
+	for _, k := range bi.keys[i] {
+		block := bi.blocks[k]
+		// Blocks are sorted, skip the indices up to i
+		start, _ := slices.BinarySearch(block, i+1)
+		for _, j := range block[start:] {
+			if bi.seen[j] != i+1 {
+				bi.seen[j] = i + 1
+				result = append(result, j)
+			}
+		}
+	}
+	slices.Sort(result)
+	return result
+}
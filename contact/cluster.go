@@ -0,0 +1,143 @@
+package contact
+
+// unionFind groups contacts into duplicate clusters. Each cluster remembers
+// its member code so that two registered members are never collapsed, even
+// through a chain of similar contacts, and the sides of the "never" overrides
+// it holds so that the two sides of an override are never collapsed either.
+type unionFind struct {
+	parent []int
+	rank   []int
+	code   []string        // Member code of the cluster, held by its root
+	never  []map[int]uint8 // Sides (1, 2 or both) of each never override, held by the root
+}
+
+func newUnionFind(contacts []Contact) *unionFind {
+	uf := newUnionFindSize(len(contacts))
+	for i := range contacts {
+		uf.code[i] = contacts[i].MemberCode
+	}
+	return uf
+}
+
+// newUnionFindSize returns a union-find of n singletons without member codes,
+// merging any two clusters.
+func newUnionFindSize(n int) *unionFind {
+	uf := &unionFind{
+		parent: make([]int, n),
+		rank:   make([]int, n),
+		code:   make([]string, n),
+	}
+	for i := range n {
+		uf.parent[i] = i
+	}
+	return uf
+}
+
+func (uf *unionFind) find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]] // Path halving
+		i = uf.parent[i]
+	}
+	return i
+}
+
+// forbid marks the cluster of i as side 1 or 2 of the never override n.
+func (uf *unionFind) forbid(i, n int, side uint8) {
+	if uf.never == nil {
+		uf.never = make([]map[int]uint8, len(uf.parent))
+	}
+	root := uf.find(i)
+	if uf.never[root] == nil {
+		uf.never[root] = make(map[int]uint8)
+	}
+	uf.never[root][n] |= side
+}
+
+// codesConflict reports whether the clusters of i and j hold two different
+// non-empty member codes.
+func (uf *unionFind) codesConflict(i, j int) bool {
+	ci, cj := uf.code[uf.find(i)], uf.code[uf.find(j)]
+	return ci != "" && cj != "" && ci != cj
+}
+
+// overridden reports whether merging the clusters of i and j would gather
+// both sides of a never override.
+func (uf *unionFind) overridden(i, j int) bool {
+	if uf.never == nil {
+		return false
+	}
+	ni, nj := uf.never[uf.find(i)], uf.never[uf.find(j)]
+	for n, si := range ni {
+		// A cluster already holding both sides, through a contact matching
+		// both keys, cannot be kept apart anymore
+		if sj := nj[n]; si|sj == 3 && si != 3 && sj != 3 {
+			return true
+		}
+	}
+	return false
+}
+
+// canUnion reports whether the clusters of i and j may be merged: they must
+// not hold two different non-empty member codes nor the two sides of a never
+// override.
+func (uf *unionFind) canUnion(i, j int) bool {
+	return !uf.codesConflict(i, j) && !uf.overridden(i, j)
+}
+
+// refusal returns why canUnion refuses to merge the clusters of i and j.
+func (uf *unionFind) refusal(i, j int) Decision {
+	if uf.codesConflict(i, j) {
+		return DecisionRefused
+	}
+	return DecisionNever
+}
+
+// union merges the clusters of i and j, unless refused by canUnion.
+// It returns whether both contacts end up in the same cluster.
+func (uf *unionFind) union(i, j int) bool {
+	ri, rj := uf.find(i), uf.find(j)
+	if ri == rj {
+		return true
+	}
+	if !uf.canUnion(ri, rj) {
+		return false
+	}
+
+	if uf.rank[ri] < uf.rank[rj] {
+		ri, rj = rj, ri
+	}
+	uf.parent[rj] = ri
+	if uf.rank[ri] == uf.rank[rj] {
+		uf.rank[ri]++
+	}
+	if uf.code[ri] == "" {
+		uf.code[ri] = uf.code[rj]
+	}
+	if uf.never != nil && uf.never[rj] != nil {
+		if uf.never[ri] == nil {
+			uf.never[ri] = make(map[int]uint8)
+		}
+		for n, side := range uf.never[rj] {
+			uf.never[ri][n] |= side
+		}
+	}
+	return true
+}
+
+// clusters returns the contact indices of each cluster. Clusters are ordered
+// by their first contact, and indices are ascending within a cluster.
+func (uf *unionFind) clusters() [][]int {
+	var result [][]int
+	position := make(map[int]int)
+	for i := range uf.parent {
+		root := uf.find(i)
+		p, ok := position[root]
+		if !ok {
+			p = len(result)
+			position[root] = p
+			result = append(result, nil)
+		}
+		result[p] = append(result[p], i)
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package contact
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnionFind(t *testing.T) {
+	contacts := []Contact{
+		{MemberCode: "1"},
+		{},
+		{MemberCode: "2"},
+		{},
+		{MemberCode: "1"},
+	}
+	uf := newUnionFind(contacts)
+
+	if !uf.union(0, 1) {
+		t.Error("union(0, 1) refused, want accepted")
+	}
+	if uf.union(1, 2) {
+		t.Error("union(1, 2) accepted, want refused: cluster already holds member code 1")
+	}
+	if !uf.union(4, 1) {
+		t.Error("union(4, 1) refused, want accepted: same member code")
+	}
+	if !uf.union(3, 2) {
+		t.Error("union(3, 2) refused, want accepted")
+	}
+
+	want := [][]int{{0, 1, 4}, {2, 3}}
+	if got := uf.clusters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("clusters() = %v, want %v", got, want)
+	}
+}
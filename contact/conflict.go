@@ -0,0 +1,68 @@
+package contact
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Conflict is a field for which two merged contacts hold different non-empty
+// values: one is kept, the other discarded. In a three-way merge, only the
+// fields changed on both sides since the previous output are conflicts.
+type Conflict struct {
+	Contact         ContactRef // Set by the Deduplicator once the contact is merged
+	Field           Field
+	Kept            string
+	Discarded       string
+	KeptSource      Source
+	DiscardedSource Source
+	Base            string // Value in the previous output, for a three-way merge
+}
+
+// normalizeValue returns the comparison form of a field value.
+func normalizeValue(f Field, v string) string {
+	switch {
+	case strings.HasPrefix(string(f), "phone:"):
+		return NormalizePhone(v)
+	case strings.HasPrefix(string(f), "email:"):
+		return NormalizeEmail(v)
+	}
+	return strings.ToLower(strings.Join(strings.Fields(v), " "))
+}
+
+// sameValue reports whether two values of a field are the same once
+// normalized, so that formatting differences are not conflicts.
+func sameValue(f Field, v1, v2 string) bool {
+	return normalizeValue(f, v1) == normalizeValue(f, v2)
+}
+
+// sortConflicts orders conflicts by field, in the order of Fields.
+func sortConflicts(conflicts []Conflict) {
+	slices.SortStableFunc(conflicts, func(a, b Conflict) int {
+		return slices.Index(Fields, a.Field) - slices.Index(Fields, b.Field)
+	})
+}
+
+// conflictsHeader is the header of the conflicts report.
+var conflictsHeader = []string{"member_code", "first_name", "last_name", "field", "kept", "discarded", "kept_source", "discarded_source", "base"}
+
+// WriteConflictsCSV writes a report with one line per conflict.
+func WriteConflictsCSV(w io.Writer, conflicts []Conflict) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(conflictsHeader); err != nil {
+		return err
+	}
+	for _, c := range conflicts {
+		record := []string{
+			c.Contact.MemberCode, c.Contact.FirstName, c.Contact.LastName,
+			string(c.Field), c.Kept, c.Discarded,
+			c.KeptSource.String(), c.DiscardedSource.String(), c.Base,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,112 @@
+package contact
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeContactConflicts(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	intranet := Source{Name: "intranet", Row: 4}
+	gmail := Source{Name: "gmail", Row: 9}
+
+	tests := []struct {
+		name        string
+		destination Contact
+		source      Contact
+		want        []Conflict
+	}{
+		{
+			name:        "Kept destination value",
+			destination: Contact{Addresses: homeAddress(Address{City: "Paris", Street: "1 rue de la Paix"})},
+			source:      Contact{Addresses: homeAddress(Address{City: "Lyon", Street: "1 RUE DE LA  PAIX", ZipCode: "69001"})},
+			want:        []Conflict{{Field: AddressField(AddressHome), Kept: "1 rue de la Paix, Paris", Discarded: "1 rue de la Paix, 69001 Lyon", KeptSource: intranet, DiscardedSource: gmail}},
+		},
+		{
+			name:        "Addresses compared normalized",
+			destination: Contact{Addresses: homeAddress(Address{Street: "1 rue de la Paix", ZipCode: "75002", City: "Paris"})},
+			source:      Contact{Addresses: homeAddress(Address{Street: "1 RUE DE LA  PAIX", ZipCode: "75002", City: "PARIS"})},
+		},
+		{
+			name:        "Kept newer source value",
+			destination: Contact{Addresses: homeAddress(Address{City: "Paris"}), UpdatedAt: &older},
+			source:      Contact{Addresses: homeAddress(Address{City: "Lyon"}), UpdatedAt: &newer},
+			want:        []Conflict{{Field: AddressField(AddressHome), Kept: "Lyon", Discarded: "Paris", KeptSource: gmail, DiscardedSource: intranet}},
+		},
+		{
+			name:        "Phones and emails compared normalized",
+			destination: Contact{Phones: map[PhoneType]string{PhoneMobile1: "06 12 34 56 78", PhoneHome: "0144444444"}, Emails: map[EmailType]string{EmailPersonal: "John@Example.com"}},
+			source:      Contact{Phones: map[PhoneType]string{PhoneMobile1: "+33612345678", PhoneHome: "0155555555"}, Emails: map[EmailType]string{EmailPersonal: "john@example.com"}},
+			want:        []Conflict{{Field: PhoneField(PhoneHome), Kept: "0144444444", Discarded: "0155555555", KeptSource: intranet, DiscardedSource: gmail}},
+		},
+		{
+			name:        "Fields ordered",
+			destination: Contact{FirstName: "Jean", Birthday: &older, Emails: map[EmailType]string{EmailPersonal: "a@example.com", EmailDedicatedSGDF: "b@sgdf.fr"}},
+			source:      Contact{FirstName: "Jeanne", Birthday: &newer, Emails: map[EmailType]string{EmailPersonal: "c@example.com", EmailDedicatedSGDF: "d@sgdf.fr"}},
+			want: []Conflict{
+				{Field: FieldFirstName, Kept: "Jean", Discarded: "Jeanne", KeptSource: intranet, DiscardedSource: gmail},
+				{Field: EmailField(EmailPersonal), Kept: "a@example.com", Discarded: "c@example.com", KeptSource: intranet, DiscardedSource: gmail},
+				{Field: EmailField(EmailDedicatedSGDF), Kept: "b@sgdf.fr", Discarded: "d@sgdf.fr", KeptSource: intranet, DiscardedSource: gmail},
+				{Field: FieldBirthday, Kept: "2025-01-01", Discarded: "2025-09-01", KeptSource: intranet, DiscardedSource: gmail},
+			},
+		},
+		{
+			name:        "No conflict when filling",
+			destination: Contact{FirstName: "John"},
+			source:      Contact{LastName: "Doe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.destination.SetSource(intranet)
+			tt.source.SetSource(gmail)
+			if got := tt.destination.MergeContact(&tt.source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeContact() conflicts = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeduplicatorConflicts(t *testing.T) {
+	contacts := []Contact{
+		{MemberCode: "123", FirstName: "Marie", LastName: "Dupont", Addresses: homeAddress(Address{City: "Paris"})},
+		{MemberCode: "123", FirstName: "Marie", LastName: "Dupont", Addresses: homeAddress(Address{City: "Lyon"})},
+	}
+	d := &Deduplicator{}
+	d.Deduplicate(contacts)
+
+	want := []Conflict{{
+		Contact:   ContactRef{MemberCode: "123", FirstName: "Marie", LastName: "Dupont"},
+		Field:     AddressField(AddressHome),
+		Kept:      "Paris",
+		Discarded: "Lyon",
+	}}
+	if !reflect.DeepEqual(d.Conflicts, want) {
+		t.Errorf("Conflicts = %+v, want %+v", d.Conflicts, want)
+	}
+}
+
+func TestWriteConflictsCSV(t *testing.T) {
+	conflicts := []Conflict{{
+		Contact:         ContactRef{MemberCode: "123", FirstName: "Marie", LastName: "Dupont"},
+		Field:           AddressField(AddressHome),
+		Kept:            "1 rue de la Paix",
+		Discarded:       "2 avenue Foch",
+		KeptSource:      Source{Name: "intranet", File: "export.xls", Row: 4},
+		DiscardedSource: Source{Name: "gmail"},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteConflictsCSV(&buf, conflicts); err != nil {
+		t.Fatalf("WriteConflictsCSV() error = %v", err)
+	}
+	want := "member_code,first_name,last_name,field,kept,discarded,kept_source,discarded_source,base\n" +
+		"123,Marie,Dupont,address:home,1 rue de la Paix,2 avenue Foch,\"intranet (export.xls, row 4)\",gmail,\n"
+	if buf.String() != want {
+		t.Errorf("WriteConflictsCSV() = %q, want %q", buf.String(), want)
+	}
+}
@@ -1,21 +1,56 @@
 package contact
 
 import (
+	"slices"
 	"time"
 )
 
 type Contact struct {
-	MemberCode string
-	FirstName  string
-	LastName   string
-	Emails     map[EmailType]string // Emails par type
-	Birthday   *time.Time
-	Address    string
-	City       string
-	ZipCode    string
-	Country    string
-	Phones     map[PhoneType]string // Numéros de téléphone par type
-	Position   string
-	Labels     []Label
-	UpdatedAt  *time.Time
+	ID         string                  `json:"id,omitempty"` // Stable identifier given by totem, see AssignID
+	MemberCode string                  `json:"member_code,omitempty"`
+	FirstName  string                  `json:"first_name,omitempty"`
+	LastName   string                  `json:"last_name,omitempty"`
+	Emails     map[EmailType]string    `json:"emails,omitempty"` // Emails par type
+	Birthday   *time.Time              `json:"birthday,omitempty"`
+	Addresses  map[AddressType]Address `json:"addresses,omitempty"` // Adresses par type
+	Phones     map[PhoneType]string    `json:"phones,omitempty"`    // Numéros de téléphone par type
+	Position   string                  `json:"position,omitempty"`
+	Labels     []Label                 `json:"labels,omitempty"`
+	Relations  []Relation              `json:"relations,omitempty"` // Children, parents and spouse
+	UpdatedAt  *time.Time              `json:"updated_at,omitempty"`
+	Provenance map[Field]Source        `json:"provenance,omitempty"` // Source of each field, when known
+	Stamps     map[Field]Stamp         `json:"stamps,omitempty"`     // Last known change of each field
+}
+
+// Key returns the key identifying a contact across runs: its ID, else its
+// member code, else its first email, normalized. It is empty for a contact
+// with none of them.
+func (c *Contact) Key() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	if c.MemberCode != "" {
+		return c.MemberCode
+	}
+	return NormalizeEmail(c.FirstEmail())
+}
+
+// Equal reports whether two contacts hold the same values, compared
+// normalized, and the same labels and relations in any order. ID,
+// provenance, stamps and UpdatedAt are not compared.
+func (c *Contact) Equal(other *Contact) bool {
+	for _, f := range Fields {
+		if !sameValue(f, c.FieldValue(f), other.FieldValue(f)) {
+			return false
+		}
+	}
+	if len(c.Labels) != len(other.Labels) {
+		return false
+	}
+	for _, label := range c.Labels {
+		if !slices.Contains(other.Labels, label) {
+			return false
+		}
+	}
+	return slices.Equal(c.relationStrings(), other.relationStrings())
 }
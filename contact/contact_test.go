@@ -0,0 +1,48 @@
+package contact
+
+import "testing"
+
+func TestContactKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		contact Contact
+		want    string
+	}{
+		{"ID", Contact{ID: "a1", MemberCode: "123"}, "a1"},
+		{"Member code", Contact{MemberCode: "123", Emails: map[EmailType]string{EmailPersonal: "a@example.com"}}, "123"},
+		{"Email", Contact{Emails: map[EmailType]string{EmailDedicatedSGDF: " A@SGDF.fr"}}, "a@sgdf.fr"},
+		{"None", Contact{FirstName: "John"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.contact.Key(); got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContactEqual(t *testing.T) {
+	c := Contact{
+		FirstName: "John",
+		Phones:    map[PhoneType]string{PhoneMobile1: "06 12 34 56 78"},
+		Labels:    []Label{LabelParent, LabelAdherent},
+	}
+	tests := []struct {
+		name  string
+		other Contact
+		want  bool
+	}{
+		{"Same normalized", Contact{FirstName: "JOHN", Phones: map[PhoneType]string{PhoneMobile1: "+33612345678"}, Labels: []Label{LabelAdherent, LabelParent}}, true},
+		{"Other phone", Contact{FirstName: "John", Phones: map[PhoneType]string{PhoneMobile1: "0700000000"}, Labels: []Label{LabelParent, LabelAdherent}}, false},
+		{"Other labels", Contact{FirstName: "John", Phones: map[PhoneType]string{PhoneMobile1: "0612345678"}, Labels: []Label{LabelParent}}, false},
+		{"Added field", Contact{FirstName: "John", Addresses: homeAddress(Address{City: "Paris"}), Phones: map[PhoneType]string{PhoneMobile1: "0612345678"}, Labels: []Label{LabelParent, LabelAdherent}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Equal(&tt.other); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
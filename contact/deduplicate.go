@@ -2,48 +2,50 @@ package contact
 
 import (
 	"strings"
+	"unicode/utf8"
 )
 
-// levenshteinDistance calculates the Levenshtein distance between two strings
+// levenshteinDistance calculates the Levenshtein distance between two strings.
+// The distance is computed on runes, so an accented letter counts as one edit.
 func levenshteinDistance(s1, s2 string) int {
-	if len(s1) == 0 {
-		return len(s2)
+	r1, r2 := []rune(s1), []rune(s2)
+	if len(r1) == 0 {
+		return len(r2)
 	}
-	if len(s2) == 0 {
-		return len(s1)
+	if len(r2) == 0 {
+		return len(r1)
 	}
 
-	// Create a matrix for dynamic programming
-	matrix := make([][]int, len(s1)+1)
-	for i := range matrix {
-		matrix[i] = make([]int, len(s2)+1)
+	// Dynamic programming keeping only the previous row of the matrix. Rows
+	// of usual names fit on the stack.
+	var buf [64]int
+	rows := buf[:]
+	if 2*(len(r2)+1) > len(buf) {
+		rows = make([]int, 2*(len(r2)+1))
 	}
-
-	// Initialize first row and column
-	for i := 0; i <= len(s1); i++ {
-		matrix[i][0] = i
-	}
-	for j := 0; j <= len(s2); j++ {
-		matrix[0][j] = j
+	prev, curr := rows[:len(r2)+1], rows[len(r2)+1:2*(len(r2)+1)]
+	for j := range prev {
+		prev[j] = j
 	}
 
-	// Fill the matrix
-	for i := 1; i <= len(s1); i++ {
-		for j := 1; j <= len(s2); j++ {
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
 			cost := 0
-			if s1[i-1] != s2[j-1] {
+			if r1[i-1] != r2[j-1] {
 				cost = 1
 			}
 
-			matrix[i][j] = min(
-				matrix[i-1][j]+1,      // deletion
-				matrix[i][j-1]+1,      // insertion
-				matrix[i-1][j-1]+cost, // substitution
+			curr[j] = min(
+				prev[j]+1,      // deletion
+				curr[j-1]+1,    // insertion
+				prev[j-1]+cost, // substitution
 			)
 		}
+		prev, curr = curr, prev
 	}
 
-	return matrix[len(s1)][len(s2)]
+	return prev[len(r2)]
 }
 
 // normalizeString normalizes a string for comparison by removing extra spaces and converting to lowercase
@@ -52,86 +54,161 @@ func normalizeString(s string) string {
 }
 
 // areNamesSimilar checks if two names are similar enough to be considered duplicates
-// Returns true if the names are identical or have a Levenshtein distance <= 2
+// Returns true if the normalized names (see NormalizeName) are identical or
+// have a Levenshtein distance <= 2
 func areNamesSimilar(name1, name2 string) bool {
-	if name1 == "" || name2 == "" {
+	return areNormalizedNamesSimilar(NormalizeName(name1), NormalizeName(name2))
+}
+
+func areNormalizedNamesSimilar(norm1, norm2 string) bool {
+	if norm1 == "" || norm2 == "" {
 		return false
 	}
 
-	norm1 := normalizeString(name1)
-	norm2 := normalizeString(name2)
-
 	// If names are identical after normalization, they're similar
 	if norm1 == norm2 {
 		return true
 	}
 
-	// Calculate Levenshtein distance
-	distance := levenshteinDistance(norm1, norm2)
-
 	// Consider names similar if distance is <= 2 (allows for 1-2 typos)
 	// But only if the names are not too short (to avoid false positives)
-	minLength := min(len(norm1), len(norm2))
-	if minLength >= 3 && distance <= 2 {
-		return true
+	len1, len2 := utf8.RuneCountInString(norm1), utf8.RuneCountInString(norm2)
+	if min(len1, len2) < 3 || max(len1-len2, len2-len1) > 2 {
+		return false // The length difference alone exceeds the distance
 	}
 
-	return false
+	return levenshteinDistance(norm1, norm2) <= 2
 }
 
-// areDuplicates checks if two contacts are duplicates based on the specified criteria
+// areDuplicates checks if two contacts are duplicates according to the
+// default matcher (see DefaultMatcherConfig)
 func areDuplicates(contact1, contact2 *Contact) bool {
-	if contact1 == nil || contact2 == nil {
-		return false
-	}
+	return NewWeightedMatcher(DefaultMatcherConfig()).Match(contact1, contact2).Duplicate
+}
 
-	// Priority 1: Same MemberCode (if both have one)
-	if contact1.MemberCode != "" && contact2.MemberCode != "" {
-		return contact1.MemberCode == contact2.MemberCode
-	}
+// Deduplicator merges the contacts a Matcher detects as duplicates.
+// The zero value uses a WeightedMatcher with the default configuration.
+type Deduplicator struct {
+	Matcher Matcher
+
+	// Overrides are manual decisions taking precedence over the matcher.
+	Overrides []Override
+
+	// Reviewer, when set, is asked about the uncertain pairs. If it fails,
+	// the error is kept in ReviewErr and the remaining pairs are decided by
+	// the matcher. The always and never answers are added to Overrides and
+	// recorded in Learned, or counted in Unrecorded when the contacts have no
+	// key to record them by (see Override).
+	Reviewer   Reviewer
+	ReviewErr  error
+	Learned    []Override
+	Unrecorded int
+	answers    map[string]Answer // Answers of the run, by pair
+
+	// Policy decides which source wins for each field when merging.
+	Policy MergePolicy
+
+	// Previous are the contacts of the previous output. When set, each
+	// cluster is merged three-way against its previous version (see
+	// MergeContactWithBase).
+	Previous []Contact
+
+	// Conflicts collects the fields for which merged contacts disagreed.
+	Conflicts []Conflict
+
+	// Explain makes Deduplicate append the decision taken for every
+	// candidate pair to Decisions.
+	Explain   bool
+	Decisions []PairDecision
+}
 
-	// Priority 2: Similar first and last names
-	if contact1.FirstName != "" && contact1.LastName != "" &&
-		contact2.FirstName != "" && contact2.LastName != "" {
-		return areNamesSimilar(contact1.FirstName, contact2.FirstName) &&
-			areNamesSimilar(contact1.LastName, contact2.LastName)
+// match compares two contacts of the block index, reusing their profiles when
+// the matcher is a WeightedMatcher.
+func (d *Deduplicator) match(contacts []Contact, index *blockIndex, i, j int) Match {
+	if m, ok := d.Matcher.(*WeightedMatcher); ok {
+		return m.matchProfiles(&contacts[i], &contacts[j], index.profiles[i], index.profiles[j], d.Explain || d.Reviewer != nil)
 	}
+	return d.Matcher.Match(&contacts[i], &contacts[j])
+}
 
-	return false
+// explain records the decision taken for a pair when explaining.
+func (d *Deduplicator) explain(contacts []Contact, i, j int, match Match, decision Decision, reviewed bool) {
+	if !d.Explain {
+		return
+	}
+	d.Decisions = append(d.Decisions, PairDecision{
+		Contact1: newContactRef(&contacts[i]),
+		Contact2: newContactRef(&contacts[j]),
+		Match:    match,
+		Decision: decision,
+		Reviewed: reviewed,
+	})
 }
 
-func DeduplicateAndMergeContacts(contacts []Contact) []Contact {
-	if len(contacts) <= 1 {
+// Deduplicate merges the contacts detected as duplicates.
+// Contacts are first grouped by blocking keys (see blockingKeys) so that only
+// contacts sharing a member code, an email, a phone or a close last name are
+// compared, keeping large exports tractable.
+// Duplicate relations are transitive: if A and B are duplicates, and B and C
+// are duplicates, A, B and C are merged together whatever the input order.
+// A cluster never gathers two different member codes, nor the two sides of a
+// never override. Contacts of an always override are merged even if they do
+// not match.
+func (d *Deduplicator) Deduplicate(contacts []Contact) []Contact {
+	if len(contacts) == 0 || (len(contacts) == 1 && len(d.Previous) == 0) {
 		return contacts
 	}
+	if d.Matcher == nil {
+		d.Matcher = NewWeightedMatcher(DefaultMatcherConfig())
+	}
 
-	var result []Contact
-	processed := make([]bool, len(contacts))
-
-	for i, contact := range contacts {
-		if processed[i] {
-			continue
-		}
-
-		// Current contact becomes the base for merging
-		mergedContact := copyContact(&contact)
-		processed[i] = true
-
-		// Look for duplicates of this contact
-		for j := i + 1; j < len(contacts); j++ {
-			if processed[j] {
-				continue
+	index := newBlockIndex(contacts)
+	uf := newUnionFind(contacts)
+	d.applyOverrides(contacts, uf)
+
+	for i := range contacts {
+		for _, j := range index.candidates(i) {
+			match := d.match(contacts, index, i, j)
+			decision, reviewed := d.review(contacts, uf, i, j, match)
+			if !reviewed {
+				decision = DecisionSeparate
+				if match.Duplicate {
+					decision = DecisionMerge
+				}
 			}
-
-			if areDuplicates(&contact, &contacts[j]) {
-				// Merge the duplicate into our base contact
-				mergedContact.MergeContact(&contacts[j])
-				processed[j] = true
+			if (decision == DecisionMerge || decision == DecisionAlways) && !uf.union(i, j) {
+				decision = uf.refusal(i, j)
 			}
+			d.explain(contacts, i, j, match, decision, reviewed)
 		}
+	}
 
+	bases := newBaseIndex(d.Previous)
+	var result []Contact
+	for _, cluster := range uf.clusters() {
+		// The first contact of the cluster becomes the base for merging
+		mergedContact := copyContact(&contacts[cluster[0]])
+		previous := bases.base(contacts, cluster)
+		var conflicts []Conflict
+		for _, j := range cluster[1:] {
+			conflicts = append(conflicts, mergedContact.MergeContactWithBase(&contacts[j], previous, d.Policy)...)
+		}
+		if mergedContact.ID == "" && previous != nil {
+			mergedContact.ID = previous.ID // A single contact has nothing to merge
+		}
+		ref := newContactRef(mergedContact)
+		for k := range conflicts {
+			conflicts[k].Contact = ref
+		}
+		d.Conflicts = append(d.Conflicts, conflicts...)
 		result = append(result, *mergedContact)
 	}
 
 	return result
 }
+
+// DeduplicateAndMergeContacts merges the contacts detected as duplicates by
+// the default matcher (see Deduplicator).
+func DeduplicateAndMergeContacts(contacts []Contact) []Contact {
+	return (&Deduplicator{}).Deduplicate(contacts)
+}
@@ -1,7 +1,10 @@
 package contact
 
 import (
+	"fmt"
+	"math/rand/v2"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -22,6 +25,8 @@ func TestLevenshteinDistance(t *testing.T) {
 		{"Insert operation", "cat", "cart", 1},
 		{"Delete operation", "cart", "cat", 1},
 		{"Multiple operations", "kitten", "sitting", 3},
+		{"Accented character", "hélène", "helene", 2},
+		{"Multibyte length", "", "élé", 3},
 	}
 
 	for _, tt := range tests {
@@ -78,6 +83,13 @@ func TestAreNamesSimilar(t *testing.T) {
 		{"Long different names", "Alexander", "Sebastian", false},
 		{"Short names similar", "Jo", "Ja", false}, // Too short, distance=1 but minLength < 3
 		{"Accented characters", "José", "Jose", true},
+		{"Accents folded", "Hélène", "HELENE", true},
+		{"Hyphen and space", "Jean-Marc", "Jean Marc", true},
+		{"Apostrophe", "D'Arc", "Darc", true},
+		{"Typographic apostrophe", "N’Diaye", "Ndiaye", true},
+		{"Short accented names", "Léa", "Lia", true},
+		{"Short different names", "Zoé", "Eva", false},
+		{"Only punctuation", "-", "-", false},
 	}
 
 	for _, tt := range tests {
@@ -197,6 +209,18 @@ func TestAreDuplicates(t *testing.T) {
 			},
 			expected: false, // Missing last name
 		},
+		{
+			name: "No MemberCode, homophone names",
+			contact1: &Contact{
+				FirstName: "Phillipe",
+				LastName:  "Gauthier",
+			},
+			contact2: &Contact{
+				FirstName: "Filip",
+				LastName:  "Gautier",
+			},
+			expected: true,
+		},
 		{
 			name: "Empty MemberCode, same names",
 			contact1: &Contact{
@@ -225,6 +249,99 @@ func TestAreDuplicates(t *testing.T) {
 			},
 			expected: true, // Fall back to name comparison since one MemberCode is empty
 		},
+		{
+			name: "Same mobile, nickname in Gmail",
+			contact1: &Contact{
+				FirstName: "Marie",
+				LastName:  "Dupont",
+				Phones:    map[PhoneType]string{PhoneMobile1: "06 12 34 56 78"},
+			},
+			contact2: &Contact{
+				FirstName: "Maman de Léo",
+				Phones:    map[PhoneType]string{PhoneMobile2: "+33612345678"},
+			},
+			expected: true,
+		},
+		{
+			name: "Same email, maiden name",
+			contact1: &Contact{
+				FirstName: "Marie",
+				LastName:  "Dupont",
+				Emails:    map[EmailType]string{EmailPersonal: "marie.durand@test.com"},
+			},
+			contact2: &Contact{
+				FirstName: "Marie",
+				LastName:  "Durand",
+				Emails:    map[EmailType]string{EmailDedicatedSGDF: "Marie.Durand@test.com"},
+			},
+			expected: true,
+		},
+		{
+			name: "Same email, contact without name",
+			contact1: &Contact{
+				FirstName: "Marie",
+				LastName:  "Dupont",
+				Emails:    map[EmailType]string{EmailPersonal: "marie@test.com"},
+			},
+			contact2: &Contact{
+				Emails: map[EmailType]string{EmailPersonal: "marie@test.com"},
+			},
+			expected: true,
+		},
+		{
+			name: "Shared family mailbox",
+			contact1: &Contact{
+				FirstName: "Marie",
+				LastName:  "Dupont",
+				Emails:    map[EmailType]string{EmailPersonal: "famille.dupont@test.com"},
+			},
+			contact2: &Contact{
+				FirstName: "Pierre",
+				LastName:  "Dupont",
+				Emails:    map[EmailType]string{EmailPersonal: "famille.dupont@test.com"},
+			},
+			expected: false,
+		},
+		{
+			name: "Guardians giving the same mobile",
+			contact1: &Contact{
+				FirstName: "Marie",
+				LastName:  "Dupont",
+				Phones:    map[PhoneType]string{PhoneMobile1: "0612345678"},
+			},
+			contact2: &Contact{
+				FirstName: "Pierre",
+				LastName:  "Dupont",
+				Phones:    map[PhoneType]string{PhoneMobile1: "0612345678"},
+			},
+			expected: false,
+		},
+		{
+			name: "Shared household landline",
+			contact1: &Contact{
+				FirstName: "Marie",
+				Phones:    map[PhoneType]string{PhoneHome: "0145678901"},
+			},
+			contact2: &Contact{
+				FirstName: "Maman de Léo",
+				Phones:    map[PhoneType]string{PhoneHome: "01 45 67 89 01"},
+			},
+			expected: false,
+		},
+		{
+			name: "Same mobile but different MemberCode",
+			contact1: &Contact{
+				MemberCode: "12345",
+				FirstName:  "Marie",
+				Phones:     map[PhoneType]string{PhoneMobile1: "0612345678"},
+			},
+			contact2: &Contact{
+				MemberCode: "67890",
+				FirstName:  "Marie",
+				Phones:     map[PhoneType]string{PhoneMobile1: "0612345678"},
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -280,7 +397,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 					MemberCode: "12345",
 					FirstName:  "John",
 					LastName:   "Doe",
-					City:       "Paris",
+					Addresses:  homeAddress(Address{City: "Paris"}),
 				},
 				{
 					FirstName: "Jane",
@@ -290,7 +407,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 					MemberCode: "12345",
 					FirstName:  "Johnny", // Different name but same code
 					LastName:   "Doe",
-					ZipCode:    "75000", // Additional info
+					Addresses:  map[AddressType]Address{AddressWork: {ZipCode: "75000"}}, // Additional info
 				},
 			},
 			expected: []Contact{
@@ -298,8 +415,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 					MemberCode: "12345",
 					FirstName:  "John", // Original preserved
 					LastName:   "Doe",
-					City:       "Paris",
-					ZipCode:    "75000", // Merged from duplicate
+					Addresses:  map[AddressType]Address{AddressHome: {City: "Paris"}, AddressWork: {ZipCode: "75000"}}, // Merged from duplicate
 				},
 				{
 					FirstName: "Jane",
@@ -313,7 +429,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 				{
 					FirstName: "John",
 					LastName:  "Doe",
-					City:      "Paris",
+					Addresses: homeAddress(Address{City: "Paris"}),
 					Emails: map[EmailType]string{
 						EmailPersonal: "john@test.com",
 					},
@@ -325,7 +441,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 				{
 					FirstName: "Jon", // Similar to John (typo)
 					LastName:  "Doe",
-					ZipCode:   "75000",
+					Addresses: map[AddressType]Address{AddressWork: {ZipCode: "75000"}},
 					Emails: map[EmailType]string{
 						EmailDedicatedSGDF: "john@sgdf.org",
 					},
@@ -335,8 +451,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 				{
 					FirstName: "John", // Original preserved
 					LastName:  "Doe",
-					City:      "Paris",
-					ZipCode:   "75000", // Merged from duplicate
+					Addresses: map[AddressType]Address{AddressHome: {City: "Paris"}, AddressWork: {ZipCode: "75000"}}, // Merged from duplicate
 					Emails: map[EmailType]string{
 						EmailPersonal:      "john@test.com",
 						EmailDedicatedSGDF: "john@sgdf.org",
@@ -355,7 +470,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 					MemberCode: "12345",
 					FirstName:  "John",
 					LastName:   "Doe",
-					City:       "Paris",
+					Addresses:  homeAddress(Address{City: "Paris"}),
 				},
 				{
 					FirstName: "Jane",
@@ -365,13 +480,13 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 					MemberCode: "12345",
 					FirstName:  "John",
 					LastName:   "Doe",
-					ZipCode:    "75000",
+					Addresses:  map[AddressType]Address{AddressWork: {ZipCode: "75000"}},
 				},
 				{
 					MemberCode: "12345",
 					FirstName:  "John",
 					LastName:   "Doe",
-					Country:    "France",
+					Addresses:  map[AddressType]Address{AddressOther: {Country: "France"}},
 				},
 			},
 			expected: []Contact{
@@ -379,9 +494,7 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 					MemberCode: "12345",
 					FirstName:  "John",
 					LastName:   "Doe",
-					City:       "Paris",
-					ZipCode:    "75000",
-					Country:    "France",
+					Addresses:  map[AddressType]Address{AddressHome: {City: "Paris"}, AddressWork: {ZipCode: "75000"}, AddressOther: {Country: "France"}},
 				},
 				{
 					FirstName: "Jane",
@@ -421,18 +534,18 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 				{
 					FirstName: "John",
 					LastName:  "Doe",
-					City:      "Paris",
+					Addresses: homeAddress(Address{City: "Paris"}),
 				},
 				{
 					FirstName: "Jon", // Typo
 					LastName:  "Doe",
-					ZipCode:   "75000",
+					Addresses: map[AddressType]Address{AddressWork: {ZipCode: "75000"}},
 				},
 				// Group 2: Jane Smith (no duplicates)
 				{
 					FirstName: "Jane",
 					LastName:  "Smith",
-					City:      "Lyon",
+					Addresses: homeAddress(Address{City: "Lyon"}),
 				},
 				// Group 3: Bob Johnson duplicates by code
 				{
@@ -451,13 +564,12 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 				{
 					FirstName: "John", // Original name preserved
 					LastName:  "Doe",
-					City:      "Paris",
-					ZipCode:   "75000", // Merged
+					Addresses: map[AddressType]Address{AddressHome: {City: "Paris"}, AddressWork: {ZipCode: "75000"}}, // Merged
 				},
 				{
 					FirstName: "Jane",
 					LastName:  "Smith",
-					City:      "Lyon",
+					Addresses: homeAddress(Address{City: "Lyon"}),
 				},
 				{
 					MemberCode: "99999",
@@ -467,6 +579,29 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Transitive duplicates whatever the order",
+			contacts: []Contact{
+				{FirstName: "Johnny", LastName: "Doe", Addresses: homeAddress(Address{City: "Paris"})},
+				{FirstName: "Jon", LastName: "Doe", Addresses: map[AddressType]Address{AddressOther: {Country: "France"}}}, // Not similar to Johnny
+				{FirstName: "John", LastName: "Doe", Addresses: map[AddressType]Address{AddressWork: {ZipCode: "75000"}}},  // Similar to both
+			},
+			expected: []Contact{
+				{FirstName: "Johnny", LastName: "Doe", Addresses: map[AddressType]Address{AddressHome: {City: "Paris"}, AddressWork: {ZipCode: "75000"}, AddressOther: {Country: "France"}}},
+			},
+		},
+		{
+			name: "Cluster never holds two member codes",
+			contacts: []Contact{
+				{MemberCode: "11111", FirstName: "John", LastName: "Doe"},
+				{FirstName: "John", LastName: "Doe", Addresses: homeAddress(Address{City: "Paris"})},
+				{MemberCode: "22222", FirstName: "John", LastName: "Doe"},
+			},
+			expected: []Contact{
+				{MemberCode: "11111", FirstName: "John", LastName: "Doe", Addresses: homeAddress(Address{City: "Paris"})},
+				{MemberCode: "22222", FirstName: "John", LastName: "Doe"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -493,3 +628,85 @@ func TestDeduplicateAndMergeContacts(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockIndexCandidates(t *testing.T) {
+	contacts := []Contact{
+		{MemberCode: "1", FirstName: "John", LastName: "Doe"},
+		{FirstName: "Jane", LastName: "Smith", Emails: map[EmailType]string{EmailPersonal: "family@test.com"}},
+		{MemberCode: "1", FirstName: "Johnny", LastName: "Martin"},
+		{FirstName: "Paul", LastName: "Durand", Emails: map[EmailType]string{EmailPersonal: " Family@Test.com"}},
+		{FirstName: "Jon", LastName: "Doez"},
+		{FirstName: "Bob", LastName: "Johnson", Phones: map[PhoneType]string{PhoneMobile1: "0612345678"}},
+		{FirstName: "Robert", LastName: "Johnsonn", Phones: map[PhoneType]string{PhoneMobile1: "+33 6 12 34 56 78"}},
+	}
+
+	index := newBlockIndex(contacts)
+
+	tests := []struct {
+		i    int
+		want []int
+	}{
+		{0, []int{2, 4}}, // Member code, last name prefix
+		{1, []int{3}},    // Normalized email
+		{2, nil},         // Only later contacts are candidates
+		{5, []int{6}},    // Normalized phone
+	}
+
+	for _, tt := range tests {
+		if got := index.candidates(tt.i); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("candidates(%d) = %v, want %v", tt.i, got, tt.want)
+		}
+	}
+}
+
+// syntheticContacts generates a territory-sized export: individuals with
+// their legal guardians, and Gmail copies with typos for a part of them.
+func syntheticContacts(n int) []Contact {
+	rng := rand.New(rand.NewPCG(1, 2))
+	syllables := []string{"ber", "mar", "du", "le", "ro", "ga", "tin", "bon", "cha", "vi",
+		"lan", "mo", "ri", "fon", "gue", "ta", "ne", "pe", "sau", "cour",
+		"del", "four", "ni", "ra", "lou", "mer", "jac", "que", "bau", "din"}
+	firstNames := []string{"Jean", "Marie", "Pierre", "Sophie", "Louis", "Camille", "Paul", "Claire",
+		"Hugo", "Emma", "Léo", "Chloé", "Lucas", "Inès", "Jules", "Léa", "Gabriel", "Manon",
+		"Arthur", "Zoé", "Thomas", "Julie", "Nicolas", "Hélène", "Antoine", "Anne", "Mathieu", "Alice"}
+
+	lastName := func() string {
+		s := syllables[rng.IntN(len(syllables))] + syllables[rng.IntN(len(syllables))] + syllables[rng.IntN(len(syllables))]
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+
+	contacts := make([]Contact, 0, n)
+	for len(contacts) < n {
+		family := lastName()
+		for k := 0; k < 4 && len(contacts) < n; k++ {
+			first := firstNames[rng.IntN(len(firstNames))]
+			c := Contact{
+				FirstName: first,
+				LastName:  family,
+				Emails:    map[EmailType]string{EmailPersonal: fmt.Sprintf("%s.%s.%d@example.com", first, family, len(contacts))},
+				Phones:    map[PhoneType]string{PhoneMobile1: fmt.Sprintf("06%08d", rng.IntN(100000000))},
+			}
+			if k == 0 || rng.IntN(3) == 0 {
+				c.MemberCode = fmt.Sprintf("%09d", len(contacts))
+			}
+			contacts = append(contacts, c)
+
+			// Gmail copy with a typo in the first name
+			if rng.IntN(5) == 0 && len(contacts) < n {
+				dup := *copyContact(&c)
+				dup.MemberCode = ""
+				dup.FirstName = string([]rune(first)[:len([]rune(first))-1])
+				contacts = append(contacts, dup)
+			}
+		}
+	}
+	return contacts
+}
+
+func BenchmarkDeduplicateAndMergeContacts(b *testing.B) {
+	contacts := syntheticContacts(50000)
+	b.ResetTimer()
+	for b.Loop() {
+		DeduplicateAndMergeContacts(contacts)
+	}
+}
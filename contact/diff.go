@@ -0,0 +1,190 @@
+package contact
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// FieldChange is a field whose value differs between two versions of a
+// contact. Labels and relations are listed sorted, comma separated.
+type FieldChange struct {
+	Field Field
+	Old   string
+	New   string
+}
+
+// ContactChange is a contact found in both exports with different values.
+type ContactChange struct {
+	Old     Contact
+	New     Contact
+	Changes []FieldChange
+}
+
+// Import returns the new version of the contact to import into Gmail, with
+// the names and emails of the old version, the Gmail one: Gmail knows the
+// contact by them, so that Merge & fix pairs the imported contact with its
+// existing copy. A new email is added in a free email slot, Merge & fix
+// keeping both, while a new name is not carried. The stamps and provenance of
+// the names and emails follow their values.
+func (change ContactChange) Import() Contact {
+	c := change.New
+	c.Stamps, c.Provenance = maps.Clone(c.Stamps), maps.Clone(c.Provenance)
+	if change.Old.FirstName != "" || change.Old.LastName != "" {
+		c.FirstName, c.LastName = change.Old.FirstName, change.Old.LastName
+	}
+	if len(change.Old.Emails) > 0 {
+		c.Emails = maps.Clone(change.Old.Emails)
+		for _, et := range emailTypes {
+			if email := change.New.GetEmail(et); email != "" {
+				c.addEmail(et, email)
+			}
+		}
+	}
+
+	fields := []Field{FieldFirstName, FieldLastName}
+	for _, et := range emailTypes {
+		fields = append(fields, EmailField(et))
+	}
+	for _, f := range fields {
+		switch value := c.FieldValue(f); {
+		case value == change.New.FieldValue(f):
+		case value == change.Old.FieldValue(f):
+			c.takeStamp(&change.Old, f)
+			c.takeProvenance(&change.Old, f)
+		default:
+			delete(c.Stamps, f)
+			delete(c.Provenance, f)
+		}
+	}
+	return c
+}
+
+// Diff is the difference between two exports.
+type Diff struct {
+	Added    []Contact // In the new export only, in its order
+	Removed  []Contact // In the old export only, in its order
+	Modified []ContactChange
+}
+
+// DiffContacts pairs the contacts of two exports the way duplicates are
+// detected, a contact of one export being paired with at most one of the
+// other, and returns the contacts added, removed and modified. A nil matcher
+// is a WeightedMatcher with the default configuration.
+func DiffContacts(old, new []Contact, matcher Matcher) Diff {
+	if matcher == nil {
+		matcher = NewWeightedMatcher(DefaultMatcherConfig())
+	}
+
+	// Compare the contacts of the old export with those of the new one
+	contacts := slices.Concat(old, new)
+	index := newBlockIndex(contacts)
+	d := &Deduplicator{Matcher: matcher}
+	type pair struct {
+		i, j  int
+		match Match
+	}
+	var pairs []pair
+	for i := range old {
+		for _, j := range index.candidates(i) {
+			if j < len(old) {
+				continue
+			}
+			if match := d.match(contacts, index, i, j); match.Duplicate {
+				pairs = append(pairs, pair{i, j, match})
+			}
+		}
+	}
+
+	// Decisive matches first, then the best scores
+	slices.SortStableFunc(pairs, func(a, b pair) int {
+		if a.match.Decisive != b.match.Decisive {
+			if a.match.Decisive {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.match.Score, a.match.Score)
+	})
+	paired := make([]int, len(contacts))
+	for k := range paired {
+		paired[k] = -1
+	}
+	for _, p := range pairs {
+		if paired[p.i] < 0 && paired[p.j] < 0 {
+			paired[p.i], paired[p.j] = p.j, p.i
+		}
+	}
+
+	var diff Diff
+	for i := range old {
+		if paired[i] < 0 {
+			diff.Removed = append(diff.Removed, old[i])
+		} else if changes := fieldChanges(&old[i], &contacts[paired[i]]); len(changes) > 0 {
+			diff.Modified = append(diff.Modified, ContactChange{Old: old[i], New: contacts[paired[i]], Changes: changes})
+		}
+	}
+	for j := range new {
+		if paired[len(old)+j] < 0 {
+			diff.Added = append(diff.Added, new[j])
+		}
+	}
+	return diff
+}
+
+// FieldID identifies the contact ID in a FieldChange.
+const FieldID Field = "id"
+
+// fieldChanges returns the ID given to the new version, then the fields whose
+// values differ once normalized, in the order of Fields, then the labels and
+// the relations.
+func fieldChanges(old, new *Contact) []FieldChange {
+	var changes []FieldChange
+	if new.ID != "" && new.ID != old.ID {
+		changes = append(changes, FieldChange{Field: FieldID, Old: old.ID, New: new.ID})
+	}
+	for _, f := range Fields {
+		if v1, v2 := old.FieldValue(f), new.FieldValue(f); !sameValue(f, v1, v2) {
+			changes = append(changes, FieldChange{Field: f, Old: v1, New: v2})
+		}
+	}
+
+	labels1, labels2 := old.LabelsAsStrings(), new.LabelsAsStrings()
+	slices.Sort(labels1)
+	slices.Sort(labels2)
+	if !slices.Equal(labels1, labels2) {
+		changes = append(changes, FieldChange{Field: FieldLabels, Old: strings.Join(labels1, ", "), New: strings.Join(labels2, ", ")})
+	}
+
+	relations1, relations2 := old.relationStrings(), new.relationStrings()
+	if !slices.Equal(relations1, relations2) {
+		changes = append(changes, FieldChange{Field: FieldRelations, Old: strings.Join(relations1, ", "), New: strings.Join(relations2, ", ")})
+	}
+	return changes
+}
+
+// WriteDiffText writes the diff as a human readable report: the added (+),
+// removed (-) and modified (~) contacts, the latter with their changed fields.
+func WriteDiffText(w io.Writer, diff Diff) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Added: %d\n", len(diff.Added))
+	for _, c := range diff.Added {
+		fmt.Fprintf(&b, "+ %s\n", newContactRef(&c))
+	}
+	fmt.Fprintf(&b, "Removed: %d\n", len(diff.Removed))
+	for _, c := range diff.Removed {
+		fmt.Fprintf(&b, "- %s\n", newContactRef(&c))
+	}
+	fmt.Fprintf(&b, "Modified: %d\n", len(diff.Modified))
+	for _, change := range diff.Modified {
+		fmt.Fprintf(&b, "~ %s\n", newContactRef(&change.New))
+		for _, fc := range change.Changes {
+			fmt.Fprintf(&b, "\t%s: %q -> %q\n", fc.Field, fc.Old, fc.New)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,145 @@
+package contact
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffContacts(t *testing.T) {
+	old := []Contact{
+		{MemberCode: "1", FirstName: "Louis", LastName: "Martin", Labels: []Label{LabelAdherent, LabelScoutGuide}},
+		{FirstName: "Marie", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "marie@example.com"}},
+		{MemberCode: "2", FirstName: "Paul", LastName: "Durand"},
+		{FirstName: "Anne", LastName: "Petit", Phones: map[PhoneType]string{PhoneMobile1: "0611111111"}},
+	}
+	new := []Contact{
+		{MemberCode: "3", FirstName: "Léa", LastName: "Bernard"},
+		{MemberCode: "1", FirstName: "Louis", LastName: "Martin", Labels: []Label{LabelPionnierCaravelle, LabelAdherent}},
+		{FirstName: "Marie", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "marie.martin@example.com"}},
+		{FirstName: "ANNE", LastName: "Petit", Phones: map[PhoneType]string{PhoneMobile1: "06 11 11 11 11"}},
+	}
+
+	got := DiffContacts(old, new, nil)
+	want := Diff{
+		Added:   []Contact{new[0]},
+		Removed: []Contact{old[2]},
+		Modified: []ContactChange{
+			{Old: old[0], New: new[1], Changes: []FieldChange{{Field: FieldLabels, Old: "Adhérent, Scout-Guide", New: "Adhérent, Pionnier-Caravelle"}}},
+			{Old: old[1], New: new[2], Changes: []FieldChange{{Field: EmailField(EmailPersonal), Old: "marie@example.com", New: "marie.martin@example.com"}}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffContacts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffContactsPairsOnce(t *testing.T) {
+	// Twins sharing the family mailbox: each is paired with a single contact
+	family := map[EmailType]string{EmailPersonal: "martin@example.com"}
+	old := []Contact{
+		{MemberCode: "1", FirstName: "Louis", LastName: "Martin", Emails: family},
+		{MemberCode: "2", FirstName: "Louise", LastName: "Martin", Emails: family},
+	}
+	new := []Contact{
+		{MemberCode: "2", FirstName: "Louise", LastName: "Martin", Emails: family},
+		{MemberCode: "1", FirstName: "Louis", LastName: "Martin", Emails: family},
+	}
+	if got := DiffContacts(old, new, nil); len(got.Added)+len(got.Removed)+len(got.Modified) != 0 {
+		t.Errorf("DiffContacts() = %+v, want no difference", got)
+	}
+}
+
+func TestDiffContactsID(t *testing.T) {
+	old := []Contact{
+		{MemberCode: "1", FirstName: "Louis", LastName: "Martin"},
+		{ID: "b2", FirstName: "Marie", LastName: "Martin"},
+	}
+	new := []Contact{
+		{ID: "a1", MemberCode: "1", FirstName: "Louis", LastName: "Martin"},
+		{FirstName: "Marie", LastName: "Martin"},
+	}
+
+	// The ID given to Louis is a change, Marie read without ID is not
+	got := DiffContacts(old, new, nil)
+	want := []ContactChange{{Old: old[0], New: new[0], Changes: []FieldChange{{Field: FieldID, New: "a1"}}}}
+	if !reflect.DeepEqual(got.Modified, want) {
+		t.Errorf("DiffContacts() modified = %+v, want %+v", got.Modified, want)
+	}
+}
+
+func TestContactChangeImport(t *testing.T) {
+	gmailTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	intranetTime := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	stamp := func(at time.Time, f Field, value string) Stamp {
+		return Stamp{Time: at, Hash: ValueHash(f, value)}
+	}
+	change := ContactChange{
+		Old: Contact{
+			ID: "a1", FirstName: "Marie", LastName: "Martin",
+			Emails: map[EmailType]string{EmailPersonal: "marie@example.com"},
+			Stamps: map[Field]Stamp{
+				FieldFirstName:            stamp(gmailTime, FieldFirstName, "Marie"),
+				EmailField(EmailPersonal): stamp(gmailTime, EmailField(EmailPersonal), "marie@example.com"),
+			},
+		},
+		New: Contact{
+			ID: "a1", FirstName: "Marie-Anne", LastName: "Martin",
+			Emails: map[EmailType]string{EmailPersonal: "marie.anne@example.com"},
+			Phones: map[PhoneType]string{PhoneMobile1: "0611111111"},
+			Stamps: map[Field]Stamp{
+				FieldFirstName:            stamp(intranetTime, FieldFirstName, "Marie-Anne"),
+				FieldLastName:             stamp(intranetTime, FieldLastName, "Martin"),
+				EmailField(EmailPersonal): stamp(intranetTime, EmailField(EmailPersonal), "marie.anne@example.com"),
+				PhoneField(PhoneMobile1):  stamp(intranetTime, PhoneField(PhoneMobile1), "0611111111"),
+			},
+		},
+	}
+
+	// The Gmail names and emails with their stamps, the new email in a free slot
+	got := change.Import()
+	want := Contact{
+		ID: "a1", FirstName: "Marie", LastName: "Martin",
+		Emails: map[EmailType]string{EmailPersonal: "marie@example.com", EmailDedicatedSGDF: "marie.anne@example.com"},
+		Phones: map[PhoneType]string{PhoneMobile1: "0611111111"},
+		Stamps: map[Field]Stamp{
+			FieldFirstName:            change.Old.Stamps[FieldFirstName],
+			FieldLastName:             change.New.Stamps[FieldLastName],
+			EmailField(EmailPersonal): change.Old.Stamps[EmailField(EmailPersonal)],
+			PhoneField(PhoneMobile1):  change.New.Stamps[PhoneField(PhoneMobile1)],
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Import() = %+v, want %+v", got, want)
+	}
+	if change.New.FirstName != "Marie-Anne" || len(change.New.Emails) != 1 || change.New.Stamps[FieldFirstName].Time != intranetTime {
+		t.Errorf("Import() modified the new version: %+v", change.New)
+	}
+
+	// Without names nor emails in Gmail, the new ones are kept
+	change.Old = Contact{ID: "a1"}
+	if got := change.Import(); got.FirstName != "Marie-Anne" || got.GetEmail(EmailPersonal) != "marie.anne@example.com" {
+		t.Errorf("Import() = %+v, want the new names and emails", got)
+	}
+}
+
+func TestWriteDiffText(t *testing.T) {
+	diff := Diff{
+		Added:   []Contact{{FirstName: "Léa", LastName: "Bernard", MemberCode: "3"}},
+		Removed: []Contact{{FirstName: "Paul", LastName: "Durand"}},
+		Modified: []ContactChange{{
+			New:     Contact{FirstName: "Marie", LastName: "Martin"},
+			Changes: []FieldChange{{Field: AddressField(AddressHome), Old: "Paris", New: ""}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDiffText(&buf, diff); err != nil {
+		t.Fatalf("WriteDiffText() error = %v", err)
+	}
+	want := "Added: 1\n+ Léa Bernard #3\nRemoved: 1\n- Paul Durand\nModified: 1\n~ Marie Martin\n\taddress:home: \"Paris\" -> \"\"\n"
+	if buf.String() != want {
+		t.Errorf("WriteDiffText() = %q, want %q", buf.String(), want)
+	}
+}
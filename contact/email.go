@@ -1,5 +1,7 @@
 package contact
 
+import "strings"
+
 type EmailType string
 
 const (
@@ -7,6 +9,9 @@ const (
 	EmailDedicatedSGDF EmailType = "DedicatedSGDF"
 )
 
+// emailTypes lists the email types in order of preference.
+var emailTypes = []EmailType{EmailPersonal, EmailDedicatedSGDF}
+
 func (c *Contact) GetEmail(et EmailType) string {
 	if c.Emails == nil {
 		return ""
@@ -22,11 +27,32 @@ func (c *Contact) SetEmail(et EmailType, email string) {
 }
 
 func (c *Contact) FirstEmail() string {
-	order := []EmailType{EmailPersonal, EmailDedicatedSGDF}
-	for _, et := range order {
+	for _, et := range emailTypes {
 		if email := c.GetEmail(et); email != "" {
 			return email
 		}
 	}
 	return ""
 }
+
+// addEmail adds an email the contact does not hold yet, typed et if that
+// type is free, else in any free type. Without a free type, it is dropped.
+func (c *Contact) addEmail(et EmailType, email string) {
+	for _, held := range c.Emails {
+		if NormalizeEmail(held) == NormalizeEmail(email) {
+			return
+		}
+	}
+	for _, t := range append([]EmailType{et}, emailTypes...) {
+		if c.GetEmail(t) == "" {
+			c.SetEmail(t, email)
+			return
+		}
+	}
+}
+
+// NormalizeEmail returns the comparison key of an email address: trimmed and
+// lowercase.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -40,3 +40,9 @@ func TestFirstEmail(t *testing.T) {
 		t.Errorf("expected 'john@example.com', got %q", got)
 	}
 }
+
+func TestNormalizeEmail(t *testing.T) {
+	if got := NormalizeEmail("  John.Doe@Example.COM "); got != "john.doe@example.com" {
+		t.Errorf("expected 'john.doe@example.com', got %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package contact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Decision is the outcome of the comparison of a candidate pair.
+type Decision string
+
+const (
+	DecisionMerge    Decision = "merge"
+	DecisionSeparate Decision = "separate"
+	// DecisionRefused is a duplicate pair left apart because merging it would
+	// gather two different member codes in a cluster.
+	DecisionRefused Decision = "refused"
+	// DecisionAlways and DecisionNever are pairs merged or left apart by an
+	// override (see Override).
+	DecisionAlways Decision = "always"
+	DecisionNever  Decision = "never"
+)
+
+// ContactRef identifies a contact in a report.
+type ContactRef struct {
+	MemberCode string `json:"member_code,omitempty"`
+	FirstName  string `json:"first_name,omitempty"`
+	LastName   string `json:"last_name,omitempty"`
+	Email      string `json:"email,omitempty"`
+}
+
+func newContactRef(c *Contact) ContactRef {
+	return ContactRef{
+		MemberCode: c.MemberCode,
+		FirstName:  c.FirstName,
+		LastName:   c.LastName,
+		Email:      c.FirstEmail(),
+	}
+}
+
+func (r ContactRef) String() string {
+	s := strings.TrimSpace(r.FirstName + " " + r.LastName)
+	if s == "" {
+		s = "(no name)"
+	}
+	if r.MemberCode != "" {
+		s += " #" + r.MemberCode
+	}
+	if r.Email != "" {
+		s += " <" + r.Email + ">"
+	}
+	return s
+}
+
+// PairDecision explains the decision taken for a candidate pair.
+type PairDecision struct {
+	Contact1 ContactRef `json:"contact1"`
+	Contact2 ContactRef `json:"contact2"`
+	Match
+	Decision Decision `json:"decision"`
+	Reviewed bool     `json:"reviewed,omitempty"` // Decided by a Reviewer
+}
+
+// WriteExplainText writes the decisions as a human readable report, one
+// block per candidate pair.
+func WriteExplainText(w io.Writer, decisions []PairDecision) error {
+	for _, d := range decisions {
+		decision := strings.ToUpper(string(d.Decision))
+		if d.Reviewed {
+			decision += " (reviewed)"
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s | %s (score %g)\n", decision, d.Contact1, d.Contact2, d.Score); err != nil {
+			return err
+		}
+		for _, e := range d.Evidence {
+			if _, err := fmt.Fprintf(w, "\t%+g\t%s\t%s\n", e.Weight, e.Signal, e.Detail); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteExplainJSON writes the decisions as an indented JSON array.
+func WriteExplainJSON(w io.Writer, decisions []PairDecision) error {
+	if decisions == nil {
+		decisions = []PairDecision{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(decisions)
+}
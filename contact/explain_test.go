@@ -0,0 +1,92 @@
+package contact
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDeduplicatorExplain(t *testing.T) {
+	contacts := []Contact{
+		{MemberCode: "123", FirstName: "John", LastName: "Doe"},
+		{FirstName: "Jon", LastName: "Doe"},
+		{MemberCode: "456", FirstName: "John", LastName: "Doe"},
+	}
+
+	d := &Deduplicator{Explain: true}
+	d.Deduplicate(contacts)
+
+	want := []struct {
+		contact1, contact2 string
+		decision           Decision
+	}{
+		{"123", "", DecisionMerge},
+		{"123", "456", DecisionSeparate},
+		{"", "456", DecisionRefused},
+	}
+	if len(d.Decisions) != len(want) {
+		t.Fatalf("Decisions = %+v, want %d decisions", d.Decisions, len(want))
+	}
+	for i, w := range want {
+		got := d.Decisions[i]
+		if got.Contact1.MemberCode != w.contact1 || got.Contact2.MemberCode != w.contact2 || got.Decision != w.decision {
+			t.Errorf("Decisions[%d] = %s | %s %s, want #%s | #%s %s",
+				i, got.Contact1, got.Contact2, got.Decision, w.contact1, w.contact2, w.decision)
+		}
+	}
+
+	if e := d.Decisions[0].Evidence; len(e) != 2 || e[0].Detail != "john / jon (distance 1)" || e[1].Detail != "doe / doe (distance 0)" {
+		t.Errorf("Decisions[0].Evidence = %+v, want first name and last name signals", e)
+	}
+
+	// Without explain, no decision is recorded
+	d = &Deduplicator{}
+	d.Deduplicate(contacts)
+	if d.Decisions != nil {
+		t.Errorf("Decisions = %+v, want nil", d.Decisions)
+	}
+}
+
+func TestWriteExplain(t *testing.T) {
+	decisions := []PairDecision{
+		{
+			Contact1: ContactRef{FirstName: "John", LastName: "Doe", MemberCode: "123"},
+			Contact2: ContactRef{FirstName: "Jon", LastName: "Doe", Email: "jon@example.com"},
+			Match: Match{
+				Score:     2,
+				Evidence:  []Evidence{{Signal: SignalFirstName, Weight: 1, Detail: "john / jon (distance 1)"}, {Signal: SignalLastName, Weight: 1, Detail: "doe / doe (distance 0)"}},
+				Duplicate: true,
+			},
+			Decision: DecisionMerge,
+		},
+	}
+
+	var text bytes.Buffer
+	if err := WriteExplainText(&text, decisions); err != nil {
+		t.Fatalf("WriteExplainText() error = %v", err)
+	}
+	wantText := "MERGE: John Doe #123 | Jon Doe <jon@example.com> (score 2)\n" +
+		"\t+1\tfirst_name\tjohn / jon (distance 1)\n" +
+		"\t+1\tlast_name\tdoe / doe (distance 0)\n"
+	if text.String() != wantText {
+		t.Errorf("WriteExplainText() = %q, want %q", text.String(), wantText)
+	}
+
+	var js bytes.Buffer
+	if err := WriteExplainJSON(&js, decisions); err != nil {
+		t.Fatalf("WriteExplainJSON() error = %v", err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatalf("WriteExplainJSON() wrote invalid JSON: %v", err)
+	}
+	if len(got) != 1 || got[0]["decision"] != "merge" || got[0]["score"] != 2.0 {
+		t.Errorf("WriteExplainJSON() = %s", js.String())
+	}
+
+	js.Reset()
+	if err := WriteExplainJSON(&js, nil); err != nil || strings.TrimSpace(js.String()) != "[]" {
+		t.Errorf("WriteExplainJSON(nil) = %q, %v, want []", js.String(), err)
+	}
+}
@@ -0,0 +1,183 @@
+package contact
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/tinque/totem/address"
+)
+
+// branchLabels are the labels of the youths of each branch, in age order.
+var branchLabels = []Label{
+	LabelFarfadet,
+	LabelLouveteauJeannette,
+	LabelScoutGuide,
+	LabelPionnierCaravelle,
+	LabelCompagnon,
+}
+
+// Household is a group of contacts living together, to whom a single letter
+// is sent.
+type Household struct {
+	Name     string       `json:"name"`             // Last names of the members
+	Address  Address      `json:"address,omitzero"` // Mailing address
+	Members  []ContactRef `json:"members"`
+	Branches []Label      `json:"branches,omitempty"` // Branches of the youths
+}
+
+// addressKey returns the normalized mailing address of a contact (see
+// Contact.MailingAddress): its parsed street line, complement excluded, and
+// its city. The key is empty when the address is incomplete.
+func addressKey(c *Contact) string {
+	a := c.MailingAddress()
+	street := address.Parse(a.Street).Key()
+	if street == "" || (a.ZipCode == "" && a.City == "") {
+		return ""
+	}
+	return street + ", " + NormalizeName(a.ZipCode+" "+a.City)
+}
+
+// BuildHouseholds groups deduplicated contacts into households: contacts
+// sharing a normalized mailing address live together, and a contact without
+// address joins the household of its first relation having one, else of its
+// first relation, e.g. a guardian missing from the intranet joins the
+// household of the youth. Relations do not join contacts living at different
+// addresses, such as a youth and a guardian living apart. Households are
+// ordered by their first contact.
+func BuildHouseholds(contacts []Contact) []Household {
+	uf := newUnionFindSize(len(contacts))
+	addresses := make(map[string]int)
+	for i := range contacts {
+		if key := addressKey(&contacts[i]); key != "" {
+			if j, ok := addresses[key]; ok {
+				uf.union(i, j)
+			} else {
+				addresses[key] = i
+			}
+		}
+	}
+
+	// A contact without address joins a single household, else it would
+	// bridge the households of relations living apart
+	related := relatedContacts(contacts)
+	for i := range contacts {
+		if addressKey(&contacts[i]) != "" || len(related[i]) == 0 {
+			continue
+		}
+		j := related[i][0]
+		for _, k := range related[i] {
+			if addressKey(&contacts[k]) != "" {
+				j = k
+				break
+			}
+		}
+		uf.union(i, j)
+	}
+
+	var households []Household
+	for _, cluster := range uf.clusters() {
+		households = append(households, newHousehold(contacts, cluster))
+	}
+	return households
+}
+
+// relatedContacts returns, for each contact, the indices of the contacts its
+// relations name, found by key, else by full name when a single contact has
+// it.
+func relatedContacts(contacts []Contact) [][]int {
+	index := relationIndex(contacts)
+	names := make(map[string]int)
+	for i := range contacts {
+		name := normalizeValue(FieldRelations, contacts[i].FullName())
+		if _, ok := names[name]; ok {
+			names[name] = -1 // Ambiguous
+		} else if name != "" {
+			names[name] = i
+		}
+	}
+
+	related := make([][]int, len(contacts))
+	for i := range contacts {
+		for _, r := range contacts[i].Relations {
+			j, ok := index[r.Key]
+			if !ok || r.Key == "" {
+				j, ok = names[normalizeValue(FieldRelations, r.Name)]
+			}
+			if ok && j >= 0 && j != i {
+				related[i] = append(related[i], j)
+			}
+		}
+	}
+	return related
+}
+
+// newHousehold describes the household of a cluster of contacts. Its address
+// is the one of the first member having one.
+func newHousehold(contacts []Contact, cluster []int) Household {
+	var h Household
+	var names []string
+	for _, i := range cluster {
+		c := &contacts[i]
+		h.Members = append(h.Members, newContactRef(c))
+		if c.LastName != "" && !slices.Contains(names, c.LastName) {
+			names = append(names, c.LastName)
+		}
+		if h.Address.IsZero() && addressKey(c) != "" {
+			h.Address = c.MailingAddress()
+		}
+		for _, branch := range branchLabels {
+			if c.HasLabel(branch) && !slices.Contains(h.Branches, branch) {
+				h.Branches = append(h.Branches, branch)
+			}
+		}
+	}
+	h.Name = strings.Join(names, " / ")
+	slices.SortFunc(h.Branches, func(a, b Label) int {
+		return slices.Index(branchLabels, a) - slices.Index(branchLabels, b)
+	})
+	return h
+}
+
+// householdsHeader is the header of the households CSV export.
+var householdsHeader = []string{"name", "address", "zip_code", "city", "country", "members", "branches"}
+
+// WriteHouseholdsCSV writes one line per household, the members and branches
+// being separated by commas.
+func WriteHouseholdsCSV(w io.Writer, households []Household) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(householdsHeader); err != nil {
+		return err
+	}
+	for _, h := range households {
+		members := make([]string, len(h.Members))
+		for i, m := range h.Members {
+			members[i] = strings.TrimSpace(m.FirstName + " " + m.LastName)
+		}
+		branches := make([]string, len(h.Branches))
+		for i, b := range h.Branches {
+			branches[i] = string(b)
+		}
+		record := []string{
+			h.Name, h.Address.Street, h.Address.ZipCode, h.Address.City, h.Address.Country,
+			strings.Join(members, ", "), strings.Join(branches, ", "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteHouseholdsJSON writes the households as an indented JSON array.
+func WriteHouseholdsJSON(w io.Writer, households []Household) error {
+	if households == nil {
+		households = []Household{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(households)
+}
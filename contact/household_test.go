@@ -0,0 +1,91 @@
+package contact
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildHouseholds(t *testing.T) {
+	contacts := []Contact{
+		{MemberCode: "1", FirstName: "Louis", LastName: "Martin", Addresses: homeAddress(Address{Street: "3 rue des Lilas", ZipCode: "75011", City: "Paris"}), Labels: []Label{LabelAdherent, LabelScoutGuide}},
+		{FirstName: "Marie", LastName: "Martin", Addresses: homeAddress(Address{Street: "Appt 12\n3 RUE DES  LILAS", ZipCode: "75011", City: "PARIS"}), Emails: map[EmailType]string{EmailPersonal: "marie@example.com"}},
+		// Without address, joins the youth
+		{FirstName: "Pierre", LastName: "Durand", Relations: []Relation{{Type: RelationChild, Name: "Louis Martin", Key: "1"}}},
+		{MemberCode: "2", FirstName: "Léa", LastName: "Martin", Addresses: homeAddress(Address{Street: "3 rue des Lilas", ZipCode: "75011", City: "Paris"}), Labels: []Label{LabelAdherent, LabelFarfadet}},
+		// Living apart from his child
+		{FirstName: "Paul", LastName: "Martin", Addresses: homeAddress(Address{Street: "8 avenue Foch", ZipCode: "69006", City: "Lyon"}), Relations: []Relation{{Type: RelationChild, Name: "Léa Martin", Key: "2"}}},
+		// Chef, related by name only
+		{MemberCode: "3", FirstName: "Anne", LastName: "Petit", Labels: []Label{LabelAdherent, LabelChefCheftaineScoutGuide}, Relations: []Relation{{Type: RelationSpouse, Name: "Paul Martin"}}},
+	}
+
+	got := BuildHouseholds(contacts)
+	if len(got) != 2 {
+		t.Fatalf("BuildHouseholds() = %d households, want 2: %+v", len(got), got)
+	}
+
+	want := Household{
+		Name:    "Martin / Durand",
+		Address: Address{Street: "3 rue des Lilas", ZipCode: "75011", City: "Paris"},
+		Members: []ContactRef{
+			newContactRef(&contacts[0]),
+			newContactRef(&contacts[1]),
+			newContactRef(&contacts[2]),
+			newContactRef(&contacts[3]),
+		},
+		Branches: []Label{LabelFarfadet, LabelScoutGuide},
+	}
+	if !reflect.DeepEqual(got[0], want) {
+		t.Errorf("BuildHouseholds()[0] = %+v, want %+v", got[0], want)
+	}
+	if got[1].Name != "Martin / Petit" || got[1].Address.City != "Lyon" || len(got[1].Members) != 2 || got[1].Branches != nil {
+		t.Errorf("BuildHouseholds()[1] = %+v, want Paul Martin and Anne Petit in Lyon", got[1])
+	}
+}
+
+func TestBuildHouseholdsSeparatedGuardians(t *testing.T) {
+	// A youth without address does not bridge the households of its guardians
+	contacts := []Contact{
+		{MemberCode: "1", FirstName: "Louis", LastName: "Martin", Relations: []Relation{{Type: RelationParent, Name: "Marie Martin"}, {Type: RelationParent, Name: "Paul Martin"}}},
+		{FirstName: "Marie", LastName: "Martin", Addresses: homeAddress(Address{Street: "3 rue des Lilas", ZipCode: "75011", City: "Paris"}), Relations: []Relation{{Type: RelationChild, Name: "Louis Martin", Key: "1"}}},
+		{FirstName: "Paul", LastName: "Martin", Addresses: homeAddress(Address{Street: "8 avenue Foch", ZipCode: "69006", City: "Lyon"}), Relations: []Relation{{Type: RelationChild, Name: "Louis Martin", Key: "1"}}},
+	}
+
+	got := BuildHouseholds(contacts)
+	if len(got) != 2 {
+		t.Fatalf("BuildHouseholds() = %d households, want 2: %+v", len(got), got)
+	}
+	if got[0].Address.City != "Paris" || len(got[0].Members) != 2 || got[1].Address.City != "Lyon" || len(got[1].Members) != 1 {
+		t.Errorf("BuildHouseholds() = %+v, want Louis with Marie in Paris and Paul in Lyon", got)
+	}
+}
+
+func TestWriteHouseholdsCSV(t *testing.T) {
+	households := []Household{{
+		Name:     "Martin",
+		Address:  Address{Street: "3 rue des Lilas\nBâtiment B", ZipCode: "75011", City: "Paris"},
+		Members:  []ContactRef{{FirstName: "Marie", LastName: "Martin"}, {MemberCode: "1", FirstName: "Louis", LastName: "Martin"}},
+		Branches: []Label{LabelScoutGuide},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteHouseholdsCSV(&buf, households); err != nil {
+		t.Fatalf("WriteHouseholdsCSV() error = %v", err)
+	}
+	want := "name,address,zip_code,city,country,members,branches\n" +
+		"Martin,\"3 rue des Lilas\nBâtiment B\",75011,Paris,,\"Marie Martin, Louis Martin\",Scout-Guide\n"
+	if buf.String() != want {
+		t.Errorf("WriteHouseholdsCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteHouseholdsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHouseholdsJSON(&buf, nil); err != nil {
+		t.Fatalf("WriteHouseholdsJSON() error = %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("WriteHouseholdsJSON(nil) = %q, want []", got)
+	}
+}
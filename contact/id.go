@@ -0,0 +1,24 @@
+package contact
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// NewID returns a new random contact ID, a version 4 UUID.
+func NewID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// AssignID gives a new ID to a contact without one. The ID is kept through
+// the Gmail round trip, identifying the contact in the next runs even
+// without member code.
+func (c *Contact) AssignID() {
+	if c.ID == "" {
+		c.ID = NewID()
+	}
+}
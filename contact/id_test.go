@@ -0,0 +1,47 @@
+package contact
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	id1, id2 := NewID(), NewID()
+	if !uuid.MatchString(id1) {
+		t.Errorf("NewID() = %q, want a version 4 UUID", id1)
+	}
+	if id1 == id2 {
+		t.Errorf("NewID() twice = %q", id1)
+	}
+}
+
+func TestAssignID(t *testing.T) {
+	c := Contact{ID: "a1"}
+	c.AssignID()
+	if c.ID != "a1" {
+		t.Errorf("AssignID() replaced ID with %q", c.ID)
+	}
+	c = Contact{}
+	c.AssignID()
+	if c.ID == "" {
+		t.Error("AssignID() did not assign an ID")
+	}
+}
+
+func TestDeduplicateByID(t *testing.T) {
+	contacts := []Contact{
+		{FirstName: "Marie", LastName: "Dupont", Addresses: homeAddress(Address{City: "Lyon"})},
+		// Renamed in Gmail after her wedding
+		{ID: "a1", FirstName: "Marie", LastName: "Durand"},
+		{ID: "a1", FirstName: "Marie", LastName: "Dupont", Addresses: homeAddress(Address{City: "Lyon"})},
+		{ID: "b2", FirstName: "Paul", LastName: "Martin"},
+	}
+	got := DeduplicateAndMergeContacts(contacts)
+	if len(got) != 2 {
+		t.Fatalf("DeduplicateAndMergeContacts() = %d contacts, want 2", len(got))
+	}
+	if got[0].ID != "a1" || got[1].ID != "b2" {
+		t.Errorf("DeduplicateAndMergeContacts() IDs = %q, %q, want a1, b2", got[0].ID, got[1].ID)
+	}
+}
@@ -0,0 +1,225 @@
+package contact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Matcher decides whether two contacts are duplicates.
+type Matcher interface {
+	Match(contact1, contact2 *Contact) Match
+}
+
+// Signal identifies a piece of evidence considered when comparing contacts.
+type Signal string
+
+const (
+	SignalID                Signal = "id"
+	SignalMemberCode        Signal = "member_code"
+	SignalFirstName         Signal = "first_name"
+	SignalLastName          Signal = "last_name"
+	SignalPhonetic          Signal = "phonetic"
+	SignalEmail             Signal = "email"
+	SignalPhone             Signal = "phone"
+	SignalBirthday          Signal = "birthday"
+	SignalZipCode           Signal = "zip_code"
+	SignalFirstNameMismatch Signal = "first_name_mismatch"
+	SignalBirthdayMismatch  Signal = "birthday_mismatch"
+)
+
+// Evidence is a signal that fired for a pair of contacts, with its
+// contribution to the score.
+type Evidence struct {
+	Signal Signal  `json:"signal"`
+	Weight float64 `json:"weight"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// Match is the outcome of the comparison of two contacts.
+type Match struct {
+	Score     float64    `json:"score"`
+	Evidence  []Evidence `json:"evidence,omitempty"`
+	Duplicate bool       `json:"duplicate"`
+	Decisive  bool       `json:"decisive,omitempty"`  // Decided by a rule rather than by the score
+	Uncertain bool       `json:"uncertain,omitempty"` // In the grey zone, worth a manual review
+}
+
+// scorer accumulates the score of a pair, and its evidence when explaining.
+type scorer struct {
+	Match
+	explain bool
+}
+
+// add scores a signal. The detail is only built when explaining, scoring
+// every candidate pair of a large export must not allocate.
+func (s *scorer) add(signal Signal, weight float64, detail func() string) {
+	if weight == 0 {
+		return
+	}
+	s.Score += weight
+	if s.explain {
+		s.Evidence = append(s.Evidence, Evidence{Signal: signal, Weight: weight, Detail: detail()})
+	}
+}
+
+// Weights are the contributions of each signal to the duplicate score.
+// Negative weights are penalties.
+type Weights struct {
+	FirstName         float64 `json:"first_name"`          // First names spelled alike
+	LastName          float64 `json:"last_name"`           // Last names spelled alike
+	Phonetic          float64 `json:"phonetic"`            // For each name only sounding alike
+	Email             float64 `json:"email"`               // A shared email address
+	Phone             float64 `json:"phone"`               // A shared mobile phone
+	Birthday          float64 `json:"birthday"`            // Same birthday
+	ZipCode           float64 `json:"zip_code"`            // Same zip code
+	FirstNameMismatch float64 `json:"first_name_mismatch"` // Fully named contacts with different first names
+	BirthdayMismatch  float64 `json:"birthday_mismatch"`   // Different birthdays
+}
+
+// MatcherConfig configures a WeightedMatcher: two contacts are duplicates when
+// their score reaches Threshold. Scores from ReviewMin up to ReviewMax
+// (excluded) are uncertain and may be reviewed manually.
+type MatcherConfig struct {
+	Weights   Weights `json:"weights"`
+	Threshold float64 `json:"threshold"`
+	ReviewMin float64 `json:"review_min"`
+	ReviewMax float64 `json:"review_max"`
+}
+
+// DefaultMatcherConfig returns the weights of the default duplicate policy:
+// similar first and last names are enough, so is a shared email or mobile
+// unless both contacts are fully named with different first names (two
+// guardians of a household sharing a family mailbox). Different birthdays are
+// not penalized, as before weighted matching: a BirthdayMismatch penalty keeps
+// apart a father and son sharing a name. Pairs falling just short of the
+// threshold are uncertain.
+func DefaultMatcherConfig() MatcherConfig {
+	return MatcherConfig{
+		Weights: Weights{
+			FirstName:         1,
+			LastName:          1,
+			Phonetic:          1,
+			Email:             2,
+			Phone:             2,
+			Birthday:          0.5,
+			ZipCode:           0.25,
+			FirstNameMismatch: -4,
+			BirthdayMismatch:  0,
+		},
+		Threshold: 2,
+		ReviewMin: 1,
+		ReviewMax: 2,
+	}
+}
+
+// LoadMatcherConfig reads a JSON matcher configuration. Settings missing from
+// the file keep their default value.
+func LoadMatcherConfig(r io.Reader) (MatcherConfig, error) {
+	cfg := DefaultMatcherConfig()
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return MatcherConfig{}, fmt.Errorf("error decoding matcher config: %v", err)
+	}
+	return cfg, nil
+}
+
+// WeightedMatcher is the default Matcher: it sums the weights of the signals
+// shared by two contacts. Two contacts with the same ID are duplicates, and
+// two contacts with member codes are duplicates if and only if the codes are
+// equal, whatever the score.
+// A WeightedMatcher caches name normalizations and is not safe for concurrent use.
+type WeightedMatcher struct {
+	Config MatcherConfig
+	names  *nameCache
+}
+
+func NewWeightedMatcher(cfg MatcherConfig) *WeightedMatcher {
+	return &WeightedMatcher{Config: cfg, names: newNameCache()}
+}
+
+func (m *WeightedMatcher) Match(contact1, contact2 *Contact) Match {
+	if contact1 == nil || contact2 == nil {
+		return Match{}
+	}
+	if m.names == nil {
+		m.names = newNameCache()
+	}
+	return m.matchProfiles(contact1, contact2, m.names.contactProfile(contact1), m.names.contactProfile(contact2), true)
+}
+
+// matchProfiles compares two contacts from their profiles, collecting the
+// evidence when explain is set.
+func (m *WeightedMatcher) matchProfiles(contact1, contact2 *Contact, p1, p2 contactProfile, explain bool) Match {
+	match := scorer{explain: explain}
+	w := m.Config.Weights
+
+	// IDs are given by totem to a single contact
+	if contact1.ID != "" && contact1.ID == contact2.ID {
+		match.Decisive = true
+		match.Duplicate = true
+		match.Score = m.Config.Threshold
+		match.Evidence = []Evidence{{Signal: SignalID, Detail: contact1.ID}}
+		return match.Match
+	}
+
+	// Member codes are unique to a person
+	if contact1.MemberCode != "" && contact2.MemberCode != "" {
+		match.Decisive = true
+		match.Duplicate = contact1.MemberCode == contact2.MemberCode
+		if match.Duplicate {
+			match.Score = m.Config.Threshold
+			match.Evidence = []Evidence{{Signal: SignalMemberCode, Detail: contact1.MemberCode}}
+		}
+		return match.Match
+	}
+
+	firstNamesMatch := m.matchName(&match, SignalFirstName, w.FirstName, p1.first, p2.first, p1.firstPhonetic, p2.firstPhonetic)
+	m.matchName(&match, SignalLastName, w.LastName, p1.last, p2.last, p1.lastPhonetic, p2.lastPhonetic)
+
+	fullNames := p1.first != "" && p1.last != "" && p2.first != "" && p2.last != ""
+	if fullNames && !firstNamesMatch {
+		match.add(SignalFirstNameMismatch, w.FirstNameMismatch, func() string { return p1.first + " / " + p2.first })
+	}
+
+	if email, ok := shared(p1.emails, p2.emails); ok {
+		match.add(SignalEmail, w.Email, func() string { return email })
+	}
+	if phone, ok := shared(p1.mobiles, p2.mobiles); ok {
+		match.add(SignalPhone, w.Phone, func() string { return phone })
+	}
+
+	if contact1.Birthday != nil && contact2.Birthday != nil {
+		b1, b2 := contact1.Birthday.Format("2006-01-02"), contact2.Birthday.Format("2006-01-02")
+		if b1 == b2 {
+			match.add(SignalBirthday, w.Birthday, func() string { return b1 })
+		} else {
+			match.add(SignalBirthdayMismatch, w.BirthdayMismatch, func() string { return b1 + " / " + b2 })
+		}
+	}
+
+	if zipCode, ok := shared(p1.zipCodes, p2.zipCodes); ok {
+		match.add(SignalZipCode, w.ZipCode, func() string { return zipCode })
+	}
+
+	match.Duplicate = match.Score >= m.Config.Threshold
+	match.Uncertain = match.Score >= m.Config.ReviewMin && match.Score < m.Config.ReviewMax
+	return match.Match
+}
+
+// matchName scores a name spelled alike with the name weight, or only
+// sounding alike with the phonetic weight. It returns whether the names match.
+func (m *WeightedMatcher) matchName(match *scorer, signal Signal, weight float64, norm1, norm2, phonetic1, phonetic2 string) bool {
+	switch {
+	case norm1 != "" && norm1 == norm2, areNormalizedNamesSimilar(norm1, norm2):
+		match.add(signal, weight, func() string {
+			return fmt.Sprintf("%s / %s (distance %d)", norm1, norm2, levenshteinDistance(norm1, norm2))
+		})
+		return true
+	case phonetic1 != "" && phonetic1 == phonetic2:
+		match.add(SignalPhonetic, m.Config.Weights.Phonetic, func() string { return string(signal) + " " + phonetic1 })
+		return true
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package contact
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMatcherConfig(t *testing.T) {
+	cfg, err := LoadMatcherConfig(strings.NewReader(`{"weights": {"email": 1}, "threshold": 3}`))
+	if err != nil {
+		t.Fatalf("LoadMatcherConfig() error = %v", err)
+	}
+
+	want := DefaultMatcherConfig()
+	want.Weights.Email = 1
+	want.Threshold = 3
+	if cfg != want {
+		t.Errorf("LoadMatcherConfig() = %+v, want %+v", cfg, want)
+	}
+
+	if _, err := LoadMatcherConfig(strings.NewReader(`{"treshold": 3}`)); err == nil {
+		t.Error("LoadMatcherConfig() with an unknown setting, want error")
+	}
+}
+
+func TestWeightedMatcherMatch(t *testing.T) {
+	birthday := time.Date(2012, 5, 3, 0, 0, 0, 0, time.UTC)
+	otherBirthday := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		config    func(*MatcherConfig)
+		contact1  Contact
+		contact2  Contact
+		score     float64
+		duplicate bool
+	}{
+		{
+			name:      "Similar names",
+			contact1:  Contact{FirstName: "John", LastName: "Doe"},
+			contact2:  Contact{FirstName: "Jon", LastName: "Doe"},
+			score:     2,
+			duplicate: true,
+		},
+		{
+			name:      "Homophone names",
+			contact1:  Contact{FirstName: "Gauthier", LastName: "Rousseau"},
+			contact2:  Contact{FirstName: "Gautier", LastName: "Rousso"},
+			score:     2,
+			duplicate: true,
+		},
+		{
+			name:      "Same name with a different birthday",
+			contact1:  Contact{FirstName: "Louis", LastName: "Martin", Birthday: &birthday},
+			contact2:  Contact{FirstName: "Louis", LastName: "Martin", Birthday: &otherBirthday},
+			score:     2,
+			duplicate: true,
+		},
+		{
+			name:      "Father and son sharing a name",
+			config:    func(cfg *MatcherConfig) { cfg.Weights.BirthdayMismatch = -1 },
+			contact1:  Contact{FirstName: "Louis", LastName: "Martin", Birthday: &birthday},
+			contact2:  Contact{FirstName: "Louis", LastName: "Martin", Birthday: &otherBirthday},
+			score:     1,
+			duplicate: false,
+		},
+		{
+			name:      "First name, birthday and zip code",
+			contact1:  Contact{FirstName: "Léo", Birthday: &birthday, Addresses: homeAddress(Address{ZipCode: "75011"})},
+			contact2:  Contact{FirstName: "Leo", LastName: "Petit", Birthday: &birthday, Addresses: homeAddress(Address{ZipCode: "75011"})},
+			score:     1.75,
+			duplicate: false,
+		},
+		{
+			name:      "Stricter threshold",
+			config:    func(cfg *MatcherConfig) { cfg.Threshold = 3 },
+			contact1:  Contact{FirstName: "John", LastName: "Doe"},
+			contact2:  Contact{FirstName: "John", LastName: "Doe"},
+			score:     2,
+			duplicate: false,
+		},
+		{
+			name:      "Decisive member code",
+			contact1:  Contact{MemberCode: "123", FirstName: "John", LastName: "Doe"},
+			contact2:  Contact{MemberCode: "456", FirstName: "John", LastName: "Doe"},
+			score:     0,
+			duplicate: false,
+		},
+		{
+			name:      "Decisive ID",
+			contact1:  Contact{ID: "a1", FirstName: "Marie", LastName: "Dupont"},
+			contact2:  Contact{ID: "a1", FirstName: "Marie", LastName: "Durand"},
+			score:     2,
+			duplicate: true,
+		},
+		{
+			name:      "Spelled alike and sounding alike",
+			config:    func(cfg *MatcherConfig) { cfg.Weights.Phonetic = 0.5 },
+			contact1:  Contact{FirstName: "John", LastName: "Doe"},
+			contact2:  Contact{FirstName: "Jon", LastName: "Doe"},
+			score:     2,
+			duplicate: true,
+		},
+		{
+			name:      "Only sounding alike",
+			config:    func(cfg *MatcherConfig) { cfg.Weights.Phonetic = 0.5 },
+			contact1:  Contact{FirstName: "Gauthier", LastName: "Rousseau"},
+			contact2:  Contact{FirstName: "Gautier", LastName: "Rousso"},
+			score:     1.5,
+			duplicate: false,
+		},
+		{
+			name:      "Different IDs",
+			contact1:  Contact{ID: "a1", FirstName: "John", LastName: "Doe"},
+			contact2:  Contact{ID: "b2", FirstName: "John", LastName: "Doe"},
+			score:     2,
+			duplicate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultMatcherConfig()
+			if tt.config != nil {
+				tt.config(&cfg)
+			}
+			got := NewWeightedMatcher(cfg).Match(&tt.contact1, &tt.contact2)
+			if got.Score != tt.score || got.Duplicate != tt.duplicate {
+				t.Errorf("Match() = score %v duplicate %t, want score %v duplicate %t (evidence %+v)",
+					got.Score, got.Duplicate, tt.score, tt.duplicate, got.Evidence)
+			}
+		})
+	}
+}
+
+// matchAll is a Matcher merging every compared pair.
+type matchAll struct{}
+
+func (matchAll) Match(contact1, contact2 *Contact) Match {
+	return Match{Duplicate: true}
+}
+
+func TestDeduplicatorCustomMatcher(t *testing.T) {
+	contacts := []Contact{
+		{FirstName: "John", LastName: "Doe", Emails: map[EmailType]string{EmailPersonal: "family@example.com"}},
+		{FirstName: "Jane", LastName: "Doe", Emails: map[EmailType]string{EmailPersonal: "family@example.com"}},
+	}
+
+	if got := DeduplicateAndMergeContacts(contacts); len(got) != 2 {
+		t.Errorf("DeduplicateAndMergeContacts() returned %d contacts, want 2", len(got))
+	}
+
+	d := &Deduplicator{Matcher: matchAll{}}
+	if got := d.Deduplicate(contacts); len(got) != 1 {
+		t.Errorf("Deduplicate() with a custom matcher returned %d contacts, want 1", len(got))
+	}
+}
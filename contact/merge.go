@@ -9,9 +9,28 @@ import "slices"
 // - If destination has UpdatedAt but source doesn't, source's data only fills empty fields
 // - If both have UpdatedAt, the one with the more recent timestamp determines the strategy
 // - If neither has UpdatedAt, source's data only fills empty fields (conservative merge)
-func (c *Contact) MergeContact(source *Contact) {
+// Fields stamped on either contact (see Stamps) are compared by their own
+// times instead, so that only the side which really changed a field wins.
+//
+// It returns the conflicts, fields for which both contacts have a different
+// non-empty value.
+func (c *Contact) MergeContact(source *Contact) []Conflict {
+	return c.MergeContactWithPolicy(source, nil)
+}
+
+// MergeContactWithPolicy is MergeContact where the rules of the policy for
+// the sources of each field (see Provenance) take precedence over the
+// timestamps: an authoritative value replaces the others, a fill-only value
+// is replaced by the others, and an ignored value is never merged. Between
+// two values of the same rule, the timestamps decide, except for two
+// fill-only values where the destination is kept.
+func (c *Contact) MergeContactWithPolicy(source *Contact, policy MergePolicy) []Conflict {
+	return c.mergeContact(source, nil, policy)
+}
+
+func (c *Contact) mergeContact(source, base *Contact, policy MergePolicy) []Conflict {
 	if source == nil {
-		return
+		return nil
 	}
 
 	// Determine merge strategy based on UpdatedAt timestamps
@@ -28,27 +47,20 @@ func (c *Contact) MergeContact(source *Contact) {
 		sourceIsNewer = source.UpdatedAt.After(*c.UpdatedAt)
 	}
 	// If both are nil, sourceIsNewer remains false (conservative merge)
+	m := &merger{c: c, source: source, base: base, sourceIsNewer: sourceIsNewer, policy: policy}
 
-	// MemberCode: merge based on strategy
-	if sourceIsNewer && source.MemberCode != "" {
-		c.MemberCode = source.MemberCode
-	} else if c.MemberCode == "" && source.MemberCode != "" {
-		c.MemberCode = source.MemberCode
+	// ID: the destination keeps its own, a contact has a single identity.
+	// Without one, the previous version's identifies the contact in the next runs
+	if c.ID == "" {
+		c.ID = source.ID
 	}
-
-	// FirstName: merge based on strategy
-	if sourceIsNewer && source.FirstName != "" {
-		c.FirstName = source.FirstName
-	} else if c.FirstName == "" && source.FirstName != "" {
-		c.FirstName = source.FirstName
+	if c.ID == "" && base != nil {
+		c.ID = base.ID
 	}
 
-	// LastName: merge based on strategy
-	if sourceIsNewer && source.LastName != "" {
-		c.LastName = source.LastName
-	} else if c.LastName == "" && source.LastName != "" {
-		c.LastName = source.LastName
-	}
+	mergeField(m, FieldMemberCode, &c.MemberCode, source.MemberCode)
+	mergeField(m, FieldFirstName, &c.FirstName, source.FirstName)
+	mergeField(m, FieldLastName, &c.LastName, source.LastName)
 
 	// Emails: merge maps based on strategy
 	if source.Emails != nil {
@@ -56,49 +68,20 @@ func (c *Contact) MergeContact(source *Contact) {
 			c.Emails = make(map[EmailType]string)
 		}
 		for emailType, email := range source.Emails {
-			if email != "" {
-				if sourceIsNewer {
-					c.Emails[emailType] = email
-				} else if c.Emails[emailType] == "" {
-					c.Emails[emailType] = email
-				}
-			}
+			mergeMapEntry(m, EmailField(emailType), c.Emails, emailType, email)
 		}
 	}
 
-	// Birthday: merge based on strategy
-	if sourceIsNewer && source.Birthday != nil {
-		c.Birthday = source.Birthday
-	} else if c.Birthday == nil && source.Birthday != nil {
-		c.Birthday = source.Birthday
-	}
-
-	// Address: merge based on strategy
-	if sourceIsNewer && source.Address != "" {
-		c.Address = source.Address
-	} else if c.Address == "" && source.Address != "" {
-		c.Address = source.Address
-	}
-
-	// City: merge based on strategy
-	if sourceIsNewer && source.City != "" {
-		c.City = source.City
-	} else if c.City == "" && source.City != "" {
-		c.City = source.City
-	}
-
-	// ZipCode: merge based on strategy
-	if sourceIsNewer && source.ZipCode != "" {
-		c.ZipCode = source.ZipCode
-	} else if c.ZipCode == "" && source.ZipCode != "" {
-		c.ZipCode = source.ZipCode
-	}
+	mergeField(m, FieldBirthday, &c.Birthday, source.Birthday)
 
-	// Country: merge based on strategy
-	if sourceIsNewer && source.Country != "" {
-		c.Country = source.Country
-	} else if c.Country == "" && source.Country != "" {
-		c.Country = source.Country
+	// Addresses: merge maps based on strategy, each address as a whole
+	if source.Addresses != nil {
+		if c.Addresses == nil {
+			c.Addresses = make(map[AddressType]Address)
+		}
+		for addressType, address := range source.Addresses {
+			mergeMapEntry(m, AddressField(addressType), c.Addresses, addressType, address)
+		}
 	}
 
 	// Phones: merge maps based on strategy
@@ -107,25 +90,14 @@ func (c *Contact) MergeContact(source *Contact) {
 			c.Phones = make(map[PhoneType]string)
 		}
 		for phoneType, phone := range source.Phones {
-			if phone != "" {
-				if sourceIsNewer {
-					c.Phones[phoneType] = phone
-				} else if c.Phones[phoneType] == "" {
-					c.Phones[phoneType] = phone
-				}
-			}
+			mergeMapEntry(m, PhoneField(phoneType), c.Phones, phoneType, phone)
 		}
 	}
 
-	// Position: merge based on strategy
-	if sourceIsNewer && source.Position != "" {
-		c.Position = source.Position
-	} else if c.Position == "" && source.Position != "" {
-		c.Position = source.Position
-	}
+	mergeField(m, FieldPosition, &c.Position, source.Position)
 
 	// Labels: always merge (add source labels not already present)
-	if source.Labels != nil {
+	if source.Labels != nil && policy.level(source, FieldLabels) > RuleIgnore.level() {
 		for _, label := range source.Labels {
 			if !slices.Contains(c.Labels, label) {
 				c.Labels = append(c.Labels, label)
@@ -133,12 +105,118 @@ func (c *Contact) MergeContact(source *Contact) {
 		}
 	}
 
+	// Relations: always merge (add source relations not already present)
+	for _, r := range source.Relations {
+		c.addRelation(r)
+	}
+
 	// UpdatedAt: keep the most recent timestamp
 	if source.UpdatedAt != nil {
 		if c.UpdatedAt == nil || source.UpdatedAt.After(*c.UpdatedAt) {
 			c.UpdatedAt = source.UpdatedAt
 		}
 	}
+
+	sortConflicts(m.conflicts)
+	return m.conflicts
+}
+
+// merger merges a source contact into a destination contact.
+type merger struct {
+	c, source     *Contact
+	base          *Contact // Previous version, for a three-way merge
+	sourceIsNewer bool
+	policy        MergePolicy
+	conflicts     []Conflict
+}
+
+// resolve decides whether the source value of a field replaces the
+// destination one, recording a conflict if both differ.
+func (m *merger) resolve(f Field, destinationEmpty bool) bool {
+	sourceLevel := m.policy.level(m.source, f)
+	if sourceLevel == RuleIgnore.level() {
+		return false
+	}
+	if destinationEmpty {
+		return true
+	}
+
+	destinationLevel := m.policy.level(m.c, f)
+	current, value := m.c.FieldValue(f), m.source.FieldValue(f)
+	differ := !sameValue(f, current, value)
+	if differ && sourceLevel == destinationLevel {
+		// Changed on one side only since the previous output
+		if replace, ok := m.resolveWithBase(f, current, value); ok {
+			return replace
+		}
+	}
+
+	replace := sourceLevel > destinationLevel ||
+		(sourceLevel == destinationLevel && sourceLevel != RuleFillOnly.level() && m.sourceIsNewerField(f))
+	if !differ {
+		// Same value: the destination gets the stamp of the source if it has none
+		if _, ok := m.c.Stamps[f]; !ok && !replace {
+			m.c.takeStamp(m.source, f)
+		}
+		return replace
+	}
+
+	conflict := Conflict{
+		Field:           f,
+		Kept:            current,
+		Discarded:       value,
+		KeptSource:      m.c.Provenance[f],
+		DiscardedSource: m.source.Provenance[f],
+	}
+	if m.base != nil {
+		conflict.Base = m.base.FieldValue(f)
+	}
+	if replace {
+		conflict.Kept, conflict.Discarded = conflict.Discarded, conflict.Kept
+		conflict.KeptSource, conflict.DiscardedSource = conflict.DiscardedSource, conflict.KeptSource
+	}
+	m.conflicts = append(m.conflicts, conflict)
+	return replace
+}
+
+// sourceIsNewerField reports whether the value of a field is newer in the
+// source. Fields stamped on either side are compared by their own times (see
+// FieldTime), others by the UpdatedAt of the contacts.
+func (m *merger) sourceIsNewerField(f Field) bool {
+	_, stamped := m.c.Stamps[f]
+	if _, ok := m.source.Stamps[f]; !ok && !stamped {
+		return m.sourceIsNewer
+	}
+	destinationTime, ok1 := fieldTime(m.c, m.source, f)
+	sourceTime, ok2 := fieldTime(m.source, m.c, f)
+	if !ok1 || !ok2 {
+		return m.sourceIsNewer
+	}
+	return sourceTime.After(destinationTime)
+}
+
+// mergeField merges a non-empty source value into the destination field dst:
+// it replaces the destination value when the source is newer, and only fills
+// an empty one otherwise. The provenance follows the value.
+func mergeField[T comparable](m *merger, f Field, dst *T, value T) {
+	var zero T
+	if value == zero || !m.resolve(f, *dst == zero) {
+		return
+	}
+	*dst = value
+	m.c.takeProvenance(m.source, f)
+	m.c.takeStamp(m.source, f)
+}
+
+// mergeMapEntry is mergeField for the emails, phones and addresses maps.
+func mergeMapEntry[K, V comparable](m *merger, f Field, dst map[K]V, key K, value V) {
+	var zero V
+	if value == zero || !m.resolve(f, dst[key] == zero) {
+		return
+	}
+	dst[key] = value
+	m.c.takeProvenance(m.source, f)
+	m.c.takeStamp(m.source, f)
 }
 
 // MergeContacts creates a new contact by merging two existing contacts.
@@ -167,13 +245,10 @@ func copyContact(c *Contact) *Contact {
 	}
 
 	copied := &Contact{
+		ID:         c.ID,
 		MemberCode: c.MemberCode,
 		FirstName:  c.FirstName,
 		LastName:   c.LastName,
-		Address:    c.Address,
-		City:       c.City,
-		ZipCode:    c.ZipCode,
-		Country:    c.Country,
 		Position:   c.Position,
 	}
 
@@ -205,6 +280,36 @@ func copyContact(c *Contact) *Contact {
 		}
 	}
 
+	// Copy addresses
+	if c.Addresses != nil {
+		copied.Addresses = make(map[AddressType]Address, len(c.Addresses))
+		for k, v := range c.Addresses {
+			copied.Addresses[k] = v
+		}
+	}
+
+	// Copy provenance
+	if c.Provenance != nil {
+		copied.Provenance = make(map[Field]Source, len(c.Provenance))
+		for k, v := range c.Provenance {
+			copied.Provenance[k] = v
+		}
+	}
+
+	// Copy stamps
+	if c.Stamps != nil {
+		copied.Stamps = make(map[Field]Stamp, len(c.Stamps))
+		for k, v := range c.Stamps {
+			copied.Stamps[k] = v
+		}
+	}
+
+	// Copy relations
+	if c.Relations != nil {
+		copied.Relations = make([]Relation, len(c.Relations))
+		copy(copied.Relations, c.Relations)
+	}
+
 	// Copy labels
 	if c.Labels != nil {
 		copied.Labels = make([]Label, len(c.Labels))
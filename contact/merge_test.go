@@ -34,19 +34,16 @@ func TestContact_MergeContact(t *testing.T) {
 			destination: &Contact{
 				FirstName: "John",
 				LastName:  "",
-				City:      "",
 			},
 			source: &Contact{
 				FirstName: "Jane", // Ne devrait pas écraser
 				LastName:  "Doe",
-				City:      "Paris",
-				ZipCode:   "75000",
+				Addresses: homeAddress(Address{City: "Paris", ZipCode: "75000"}),
 			},
 			expected: &Contact{
-				FirstName: "John",  // Conservé
-				LastName:  "Doe",   // Ajouté
-				City:      "Paris", // Ajouté
-				ZipCode:   "75000", // Ajouté
+				FirstName: "John",                                                // Conservé
+				LastName:  "Doe",                                                 // Ajouté
+				Addresses: homeAddress(Address{City: "Paris", ZipCode: "75000"}), // Ajouté
 			},
 		},
 		{
@@ -145,7 +142,7 @@ func TestContact_MergeContact(t *testing.T) {
 			destination: &Contact{
 				FirstName: "John",
 				LastName:  "OldName",
-				City:      "OldCity",
+				Addresses: homeAddress(Address{City: "OldCity"}),
 				UpdatedAt: func() *time.Time { t := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal: "old@email.com",
@@ -154,7 +151,7 @@ func TestContact_MergeContact(t *testing.T) {
 			source: &Contact{
 				FirstName: "Jane",
 				LastName:  "NewName",
-				City:      "NewCity",
+				Addresses: homeAddress(Address{City: "NewCity"}),
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal:      "new@email.com",
@@ -162,9 +159,9 @@ func TestContact_MergeContact(t *testing.T) {
 				},
 			},
 			expected: &Contact{
-				FirstName: "Jane",    // Écrasé car source plus récent
-				LastName:  "NewName", // Écrasé car source plus récent
-				City:      "NewCity", // Écrasé car source plus récent
+				FirstName: "Jane",                                // Écrasé car source plus récent
+				LastName:  "NewName",                             // Écrasé car source plus récent
+				Addresses: homeAddress(Address{City: "NewCity"}), // Écrasé car source plus récent
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal:      "new@email.com", // Écrasé car source plus récent
@@ -177,7 +174,7 @@ func TestContact_MergeContact(t *testing.T) {
 			destination: &Contact{
 				FirstName: "John",
 				LastName:  "NewName",
-				City:      "NewCity",
+				Addresses: homeAddress(Address{City: "NewCity"}),
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal: "new@email.com",
@@ -186,8 +183,10 @@ func TestContact_MergeContact(t *testing.T) {
 			source: &Contact{
 				FirstName: "Jane",
 				LastName:  "OldName",
-				City:      "OldCity",
-				ZipCode:   "12345", // Nouveau champ
+				Addresses: map[AddressType]Address{
+					AddressHome: {City: "OldCity"},
+					AddressWork: {ZipCode: "12345"}, // Nouvelle adresse
+				},
 				UpdatedAt: func() *time.Time { t := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal:      "old@email.com",
@@ -197,8 +196,10 @@ func TestContact_MergeContact(t *testing.T) {
 			expected: &Contact{
 				FirstName: "John",    // Conservé car destination plus récente
 				LastName:  "NewName", // Conservé car destination plus récente
-				City:      "NewCity", // Conservé car destination plus récente
-				ZipCode:   "12345",   // Ajouté car vide dans destination
+				Addresses: map[AddressType]Address{
+					AddressHome: {City: "NewCity"},  // Conservé car destination plus récente
+					AddressWork: {ZipCode: "12345"}, // Ajoutée car absente dans destination
+				},
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal:      "new@email.com", // Conservé car destination plus récente
@@ -211,7 +212,7 @@ func TestContact_MergeContact(t *testing.T) {
 			destination: &Contact{
 				FirstName: "John",
 				LastName:  "OldName",
-				City:      "OldCity",
+				Addresses: homeAddress(Address{City: "OldCity"}),
 				UpdatedAt: nil, // Pas de date (ancien contact)
 				Emails: map[EmailType]string{
 					EmailPersonal: "old@email.com",
@@ -220,7 +221,7 @@ func TestContact_MergeContact(t *testing.T) {
 			source: &Contact{
 				FirstName: "Jane",
 				LastName:  "NewName",
-				City:      "NewCity",
+				Addresses: homeAddress(Address{City: "NewCity"}),
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal:      "new@email.com",
@@ -228,9 +229,9 @@ func TestContact_MergeContact(t *testing.T) {
 				},
 			},
 			expected: &Contact{
-				FirstName: "Jane",    // Écrasé car source a UpdatedAt
-				LastName:  "NewName", // Écrasé car source a UpdatedAt
-				City:      "NewCity", // Écrasé car source a UpdatedAt
+				FirstName: "Jane",                                // Écrasé car source a UpdatedAt
+				LastName:  "NewName",                             // Écrasé car source a UpdatedAt
+				Addresses: homeAddress(Address{City: "NewCity"}), // Écrasé car source a UpdatedAt
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal:      "new@email.com", // Écrasé car source a UpdatedAt
@@ -243,7 +244,7 @@ func TestContact_MergeContact(t *testing.T) {
 			destination: &Contact{
 				FirstName: "John",
 				LastName:  "NewName",
-				City:      "NewCity",
+				Addresses: homeAddress(Address{City: "NewCity"}),
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal: "new@email.com",
@@ -252,9 +253,11 @@ func TestContact_MergeContact(t *testing.T) {
 			source: &Contact{
 				FirstName: "Jane",
 				LastName:  "OldName",
-				City:      "OldCity",
-				ZipCode:   "12345", // Nouveau champ
-				UpdatedAt: nil,     // Pas de date (ancien contact)
+				Addresses: map[AddressType]Address{
+					AddressHome: {City: "OldCity"},
+					AddressWork: {ZipCode: "12345"}, // Nouvelle adresse
+				},
+				UpdatedAt: nil, // Pas de date (ancien contact)
 				Emails: map[EmailType]string{
 					EmailPersonal:      "old@email.com",
 					EmailDedicatedSGDF: "jane@sgdf.org", // Nouveau email
@@ -263,8 +266,10 @@ func TestContact_MergeContact(t *testing.T) {
 			expected: &Contact{
 				FirstName: "John",    // Conservé car destination a UpdatedAt
 				LastName:  "NewName", // Conservé car destination a UpdatedAt
-				City:      "NewCity", // Conservé car destination a UpdatedAt
-				ZipCode:   "12345",   // Ajouté car vide dans destination
+				Addresses: map[AddressType]Address{
+					AddressHome: {City: "NewCity"},  // Conservé car destination a UpdatedAt
+					AddressWork: {ZipCode: "12345"}, // Ajoutée car absente dans destination
+				},
 				UpdatedAt: func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
 				Emails: map[EmailType]string{
 					EmailPersonal:      "new@email.com", // Conservé car destination a UpdatedAt
@@ -283,9 +288,9 @@ func TestContact_MergeContact(t *testing.T) {
 				},
 			},
 			source: &Contact{
-				FirstName: "Jane",  // Ne devrait pas écraser
-				LastName:  "New",   // Ne devrait pas écraser
-				ZipCode:   "12345", // Devrait être ajouté
+				FirstName: "Jane",                                 // Ne devrait pas écraser
+				LastName:  "New",                                  // Ne devrait pas écraser
+				Addresses: homeAddress(Address{ZipCode: "12345"}), // Devrait être ajouté
 				UpdatedAt: nil,
 				Emails: map[EmailType]string{
 					EmailPersonal:      "new@email.com", // Ne devrait pas écraser
@@ -293,9 +298,9 @@ func TestContact_MergeContact(t *testing.T) {
 				},
 			},
 			expected: &Contact{
-				FirstName: "John",     // Conservé (merge conservateur)
-				LastName:  "Existing", // Conservé (merge conservateur)
-				ZipCode:   "12345",    // Ajouté car vide
+				FirstName: "John",                                 // Conservé (merge conservateur)
+				LastName:  "Existing",                             // Conservé (merge conservateur)
+				Addresses: homeAddress(Address{ZipCode: "12345"}), // Ajouté car vide
 				UpdatedAt: nil,
 				Emails: map[EmailType]string{
 					EmailPersonal:      "existing@email.com", // Conservé (merge conservateur)
@@ -367,15 +372,15 @@ func TestMergeContacts(t *testing.T) {
 			source: &Contact{
 				FirstName: "Jane", // Ne devrait pas écraser dans le résultat
 				LastName:  "Doe",
-				City:      "Paris",
+				Addresses: homeAddress(Address{City: "Paris"}),
 				Emails: map[EmailType]string{
 					EmailDedicatedSGDF: "john@sgdf.org",
 				},
 			},
 			expected: &Contact{
-				FirstName: "John",  // Conservé de destination
-				LastName:  "Doe",   // Ajouté de source
-				City:      "Paris", // Ajouté de source
+				FirstName: "John",                              // Conservé de destination
+				LastName:  "Doe",                               // Ajouté de source
+				Addresses: homeAddress(Address{City: "Paris"}), // Ajouté de source
 				Birthday:  &birthday,
 				Emails: map[EmailType]string{
 					EmailPersonal:      "john@test.com",
@@ -436,10 +441,7 @@ func TestCopyContact(t *testing.T) {
 				LastName:   "Doe",
 				Birthday:   &birthday,
 				UpdatedAt:  &updatedAt,
-				Address:    "123 Rue de la Paix",
-				City:       "Paris",
-				ZipCode:    "75000",
-				Country:    "France",
+				Addresses:  homeAddress(Address{Street: "123 Rue de la Paix", City: "Paris", ZipCode: "75000", Country: "France"}),
 				Position:   "Chef",
 				Emails: map[EmailType]string{
 					EmailPersonal:      "john@personal.com",
@@ -457,10 +459,7 @@ func TestCopyContact(t *testing.T) {
 				LastName:   "Doe",
 				Birthday:   &birthday,
 				UpdatedAt:  &updatedAt,
-				Address:    "123 Rue de la Paix",
-				City:       "Paris",
-				ZipCode:    "75000",
-				Country:    "France",
+				Addresses:  homeAddress(Address{Street: "123 Rue de la Paix", City: "Paris", ZipCode: "75000", Country: "France"}),
 				Position:   "Chef",
 				Emails: map[EmailType]string{
 					EmailPersonal:      "john@personal.com",
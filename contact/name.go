@@ -0,0 +1,46 @@
+package contact
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ligatures are expanded before comparison, they are not decomposed by NFD
+var ligatures = strings.NewReplacer("œ", "oe", "æ", "ae", "ß", "ss")
+
+// NormalizeName returns the comparison key of a name: lowercase, without
+// diacritics, hyphens turned into spaces, apostrophes removed and spaces
+// collapsed. "Jean-Marc" and "jean marc" share the key "jean marc", "D'Arc"
+// and "Darc" share "darc", "Hélène" becomes "helene".
+func NormalizeName(name string) string {
+	s := ligatures.Replace(normalizeString(name))
+	s = foldDiacritics(s)
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\'' || r == '’' || r == '‘' || r == '`' || r == '´':
+			// Apostrophes are dropped: "d'arc" -> "darc"
+		case r == '-' || r == '‐' || r == '–' || r == '_' || r == '.' || unicode.IsSpace(r):
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// foldDiacritics removes accents and other combining marks: "é" -> "e".
+func foldDiacritics(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return folded
+}
@@ -0,0 +1,30 @@
+package contact
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Lowercase", "DUPONT", "dupont"},
+		{"Accents", "Hélène", "helene"},
+		{"Cedilla", "François", "francois"},
+		{"Hyphen", "Jean-Marc", "jean marc"},
+		{"Apostrophe", "D'Arc", "darc"},
+		{"Typographic apostrophe", "L’Hôpital", "lhopital"},
+		{"Ligature", "Lætitia Bœuf", "laetitia boeuf"},
+		{"Spaces collapsed", "  Marie   Claire ", "marie claire"},
+		{"Decomposed accents", "He\u0301le\u0300ne", "helene"},
+		{"Empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.input); got != tt.expected {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
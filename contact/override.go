@@ -0,0 +1,165 @@
+package contact
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// OverrideAction is the manual decision of an override.
+type OverrideAction string
+
+const (
+	OverrideAlways OverrideAction = "always" // Always merge the contacts
+	OverrideNever  OverrideAction = "never"  // Never merge the contacts
+)
+
+// Override is a manual deduplication decision between the contacts
+// identified by two keys. A key is a member code, an email address or a
+// contact ID (see Contact.AssignID).
+type Override struct {
+	Action OverrideAction
+	Key1   string
+	Key2   string
+}
+
+// overridesHeader is the optional first line of an overrides file.
+var overridesHeader = []string{"action", "key1", "key2"}
+
+// normalizeKey normalizes an override key: emails are compared normalized,
+// member codes and IDs as is.
+func normalizeKey(key string) string {
+	if strings.Contains(key, "@") {
+		return NormalizeEmail(key)
+	}
+	return strings.TrimSpace(key)
+}
+
+// LoadOverrides reads an overrides CSV file with one "action,key1,key2" line
+// per override, action being always or never. Extra columns are ignored and
+// may hold a comment, lines starting with # are skipped.
+func LoadOverrides(r io.Reader) ([]Override, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var overrides []Override
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading overrides: %v", err)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), overridesHeader[0]) {
+			continue
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("override %v: want action, key1 and key2", record)
+		}
+
+		o := Override{
+			Action: OverrideAction(strings.ToLower(strings.TrimSpace(record[0]))),
+			Key1:   normalizeKey(record[1]),
+			Key2:   normalizeKey(record[2]),
+		}
+		if o.Action != OverrideAlways && o.Action != OverrideNever {
+			return nil, fmt.Errorf("override %v: unknown action %q, want always or never", record, record[0])
+		}
+		if o.Key1 == "" || o.Key2 == "" {
+			return nil, fmt.Errorf("override %v: empty key", record)
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// WriteOverrides writes overrides in the format read by LoadOverrides,
+// starting with a header line when header is set.
+func WriteOverrides(w io.Writer, overrides []Override, header bool) error {
+	writer := csv.NewWriter(w)
+	if header {
+		if err := writer.Write(overridesHeader); err != nil {
+			return err
+		}
+	}
+	for _, o := range overrides {
+		if err := writer.Write([]string{string(o.Action), o.Key1, o.Key2}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// contactsByKey returns the indices of the contacts whose member code, ID or
+// one of whose emails is an override key.
+func contactsByKey(contacts []Contact, overrides []Override) map[string][]int {
+	keys := make(map[string][]int)
+	for _, o := range overrides {
+		keys[o.Key1], keys[o.Key2] = nil, nil
+	}
+
+	add := func(key string, i int) {
+		indices, ok := keys[key]
+		if ok && (len(indices) == 0 || indices[len(indices)-1] != i) {
+			keys[key] = append(indices, i)
+		}
+	}
+	for i := range contacts {
+		if contacts[i].MemberCode != "" {
+			add(contacts[i].MemberCode, i)
+		}
+		if contacts[i].ID != "" {
+			add(contacts[i].ID, i)
+		}
+		for _, email := range contacts[i].Emails {
+			add(NormalizeEmail(email), i)
+		}
+	}
+	return keys
+}
+
+// applyOverrides records the never overrides in the union-find, then merges
+// the contacts of the always overrides. A never override wins over a
+// conflicting always override.
+func (d *Deduplicator) applyOverrides(contacts []Contact, uf *unionFind) {
+	if len(d.Overrides) == 0 {
+		return
+	}
+	keys := contactsByKey(contacts, d.Overrides)
+
+	for n, o := range d.Overrides {
+		if o.Action != OverrideNever {
+			continue
+		}
+		for _, i := range keys[o.Key1] {
+			uf.forbid(i, n, 1)
+		}
+		for _, j := range keys[o.Key2] {
+			uf.forbid(j, n, 2)
+		}
+	}
+
+	for _, o := range d.Overrides {
+		if o.Action != OverrideAlways {
+			continue
+		}
+		for _, i := range keys[o.Key1] {
+			for _, j := range keys[o.Key2] {
+				if i == j {
+					continue
+				}
+				decision := DecisionAlways
+				if !uf.union(i, j) {
+					decision = uf.refusal(i, j)
+				}
+				d.explain(contacts, i, j, Match{Duplicate: true, Decisive: true}, decision, false)
+			}
+		}
+	}
+}
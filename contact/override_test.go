@@ -0,0 +1,168 @@
+package contact
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	input := `action,key1,key2
+# Twins
+never, louis.martin@example.com , Louise.Martin@Example.com
+always,123456789,marie.dupont@example.com,maiden name
+`
+	got, err := LoadOverrides(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("LoadOverrides() error = %v", err)
+	}
+	want := []Override{
+		{Action: OverrideNever, Key1: "louis.martin@example.com", Key2: "louise.martin@example.com"},
+		{Action: OverrideAlways, Key1: "123456789", Key2: "marie.dupont@example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadOverrides() = %+v, want %+v", got, want)
+	}
+
+	for _, input := range []string{
+		"merge,a@example.com,b@example.com",
+		"never,a@example.com",
+		"never,a@example.com,",
+	} {
+		if _, err := LoadOverrides(strings.NewReader(input)); err == nil {
+			t.Errorf("LoadOverrides(%q), want error", input)
+		}
+	}
+}
+
+func TestDeduplicatorOverrides(t *testing.T) {
+	twins := []Contact{
+		{FirstName: "Louis", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "louis.martin@example.com"}},
+		{FirstName: "Louise", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "louise.martin@example.com"}},
+		{FirstName: "Louis", LastName: "Martin", Phones: map[PhoneType]string{PhoneMobile1: "0612345678"}},
+	}
+	familyMailbox := []Contact{
+		{ID: "a1", FirstName: "Louis", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "famille.martin@example.com"}},
+		{ID: "b2", FirstName: "Louise", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "famille.martin@example.com"}},
+	}
+	maidenName := []Contact{
+		{MemberCode: "123456789", FirstName: "Marie", LastName: "Durand"},
+		{FirstName: "Marie", LastName: "Dupont", Emails: map[EmailType]string{EmailPersonal: "Marie.Dupont@example.com"}},
+	}
+
+	tests := []struct {
+		name      string
+		contacts  []Contact
+		overrides []Override
+		want      int
+	}{
+		{
+			name:     "Twins merged without override",
+			contacts: twins,
+			want:     1,
+		},
+		{
+			name:      "Twins kept apart, even through a third contact",
+			contacts:  twins,
+			overrides: []Override{{Action: OverrideNever, Key1: "louis.martin@example.com", Key2: "louise.martin@example.com"}},
+			want:      2,
+		},
+		{
+			name:     "Twins sharing an email merged without override",
+			contacts: familyMailbox,
+			want:     1,
+		},
+		{
+			name:      "Twins sharing an email kept apart by ID",
+			contacts:  familyMailbox,
+			overrides: []Override{{Action: OverrideNever, Key1: "a1", Key2: "b2"}},
+			want:      2,
+		},
+		{
+			name:     "Maiden name not merged without override",
+			contacts: maidenName,
+			want:     2,
+		},
+		{
+			name:      "Maiden name merged by member code and email",
+			contacts:  maidenName,
+			overrides: []Override{{Action: OverrideAlways, Key1: "123456789", Key2: "marie.dupont@example.com"}},
+			want:      1,
+		},
+		{
+			name:     "Never wins over always",
+			contacts: maidenName,
+			overrides: []Override{
+				{Action: OverrideAlways, Key1: "123456789", Key2: "marie.dupont@example.com"},
+				{Action: OverrideNever, Key1: "marie.dupont@example.com", Key2: "123456789"},
+			},
+			want: 2,
+		},
+		{
+			name:      "Unknown keys are ignored",
+			contacts:  maidenName,
+			overrides: []Override{{Action: OverrideAlways, Key1: "000", Key2: "nobody@example.com"}},
+			want:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deduplicator{Overrides: tt.overrides, Explain: true}
+			if got := d.Deduplicate(tt.contacts); len(got) != tt.want {
+				t.Errorf("Deduplicate() returned %d contacts, want %d (decisions %+v)", len(got), tt.want, d.Decisions)
+			}
+		})
+	}
+}
+
+func TestDeduplicatorOverridesExplain(t *testing.T) {
+	contacts := []Contact{
+		{FirstName: "Louis", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "louis.martin@example.com"}},
+		{FirstName: "Louise", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "louise.martin@example.com"}},
+		{MemberCode: "123", FirstName: "Marie", LastName: "Durand"},
+		{FirstName: "Marie", LastName: "Dupont", Emails: map[EmailType]string{EmailPersonal: "marie.dupont@example.com"}},
+	}
+	d := &Deduplicator{
+		Overrides: []Override{
+			{Action: OverrideNever, Key1: "louis.martin@example.com", Key2: "louise.martin@example.com"},
+			{Action: OverrideAlways, Key1: "123", Key2: "marie.dupont@example.com"},
+		},
+		Explain: true,
+	}
+	d.Deduplicate(contacts)
+
+	decisions := make(map[Decision]int)
+	for _, pd := range d.Decisions {
+		decisions[pd.Decision]++
+	}
+	if decisions[DecisionNever] != 1 || decisions[DecisionAlways] != 1 {
+		t.Errorf("Decisions = %+v, want one never and one always decision", d.Decisions)
+	}
+}
+
+func TestWriteOverrides(t *testing.T) {
+	overrides := []Override{
+		{Action: OverrideNever, Key1: "louis.martin@example.com", Key2: "louise.martin@example.com"},
+		{Action: OverrideAlways, Key1: "123456789", Key2: "marie.dupont@example.com"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOverrides(&buf, overrides, true); err != nil {
+		t.Fatalf("WriteOverrides() error = %v", err)
+	}
+	// Appending to an existing file, without header
+	if err := WriteOverrides(&buf, overrides[:1], false); err != nil {
+		t.Fatalf("WriteOverrides() error = %v", err)
+	}
+
+	got, err := LoadOverrides(&buf)
+	if err != nil {
+		t.Fatalf("LoadOverrides() error = %v", err)
+	}
+	want := append(overrides, overrides[0])
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadOverrides(WriteOverrides()) = %+v, want %+v", got, want)
+	}
+}
@@ -1,5 +1,7 @@
 package contact
 
+import "strings"
+
 type PhoneType string
 
 const (
@@ -32,3 +34,24 @@ func (c *Contact) FirstPhone() string {
 	}
 	return ""
 }
+
+// NormalizePhone returns the comparison key of a phone number: digits only,
+// French international prefixes (+33, 0033) turned into the national 0.
+// "+33 6 12 34 56 78" and "06.12.34.56.78" both give "0612345678".
+func NormalizePhone(number string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(number) {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else if r == '+' && i == 0 {
+			b.WriteString("00")
+		}
+	}
+	digits := b.String()
+
+	if rest, ok := strings.CutPrefix(digits, "0033"); ok {
+		// "+33 (0)6..." keeps its trunk prefix
+		return "0" + strings.TrimPrefix(rest, "0")
+	}
+	return digits
+}
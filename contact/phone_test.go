@@ -41,3 +41,24 @@ func TestFirstPhone(t *testing.T) {
 		t.Errorf("FirstPhone() = %v, want %v", got, "0612345678")
 	}
 }
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"06 12 34 56 78", "0612345678"},
+		{"06.12.34.56.78", "0612345678"},
+		{"+33 6 12 34 56 78", "0612345678"},
+		{"+33 (0)6 12 34 56 78", "0612345678"},
+		{"0033612345678", "0612345678"},
+		{"+32 470 12 34 56", "0032470123456"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePhone(tt.input); got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
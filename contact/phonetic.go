@@ -0,0 +1,112 @@
+package contact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// phoneticRule rewrites a spelling into its French pronunciation.
+type phoneticRule struct {
+	pattern *regexp.Regexp
+	repl    string
+}
+
+func rule(pattern, repl string) phoneticRule {
+	return phoneticRule{pattern: regexp.MustCompile(pattern), repl: repl}
+}
+
+// phoneticRules are applied in order on a lowercase word without diacritics
+// nor doubled letters. They are inspired by the Soundex-FR and Phonex
+// algorithms: consonants are written as they sound, vowel groups are reduced
+// and nasal vowels are encoded as digits (1 for "in", 2 for "an", 3 for "on")
+// so that "Martin" and "Martine" stay apart.
+var phoneticRules = []phoneticRule{
+	// Consonants
+	rule(`ph`, "f"),
+	rule(`sch|sh`, "ʃ"),
+	rule(`ch([lr])`, "k$1"), // Christophe, Chloé
+	rule(`ch`, "ʃ"),
+	rule(`h`, ""),
+	rule(`ck|qu|q`, "k"),
+	rule(`c([eiy])`, "s$1"),
+	rule(`c`, "k"),
+	rule(`g([eiy])`, "j$1"),
+	rule(`gu([eiy])`, "g$1"),
+	rule(`je([aou])`, "j$1"),
+	rule(`gn`, "ni"),
+	rule(`w`, "v"),
+	rule(`x$`, ""),
+	rule(`x`, "ks"),
+	rule(`z`, "s"),
+	rule(`y`, "i"),
+	rule(`bv`, "v"),
+	rule(`([aeiou])s([aeiou])`, "${1}z$2"),
+
+	// Nasal vowels, before a consonant or at the end of the word
+	rule(`(ai|ei|i|u)[nm]([^aeiou]|$)`, "1$2"),
+	rule(`(ie)n([^aeiou]|$)`, "i1$2"),
+	rule(`(e|a)[nm]([^aeiou1]|$)`, "2$2"),
+	rule(`o[nm]([^aeiou]|$)`, "3$1"),
+
+	// Vowel groups
+	rule(`eau|au`, "o"),
+	rule(`ou`, "u"),
+	rule(`oi`, "oa"),
+	rule(`ai|ei`, "e"),
+	rule(`oeu|eu`, "e"),
+	rule(`e(r|z|t)$`, "e"),
+
+	// Silent endings
+	rule(`e?s$`, ""),
+	rule(`([^aeiou])e$`, "$1"),
+	rule(`[dt]$`, ""),
+	rule(`ʃ`, "ch"),
+}
+
+// PhoneticKey returns a French phonetic encoding of a name, so that names
+// which sound alike share the same key: "Phillipe" and "Filip" both give
+// "filip", "Gauthier" and "Gautier" both give "gotie". Each word of a
+// compound name is encoded separately.
+func PhoneticKey(name string) string {
+	// The cedilla is lost when folding diacritics, keep its sound
+	name = strings.NewReplacer("ç", "s", "Ç", "s").Replace(name)
+
+	words := strings.Fields(NormalizeName(name))
+	for i, w := range words {
+		words[i] = phoneticWord(w)
+	}
+	return strings.Join(words, " ")
+}
+
+func phoneticWord(word string) string {
+	// Keep letters only, digits and symbols carry no sound
+	var b strings.Builder
+	for _, r := range word {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	s := collapseDoubles(b.String())
+	if s == "" {
+		return ""
+	}
+
+	for _, r := range phoneticRules {
+		s = r.pattern.ReplaceAllString(s, r.repl)
+	}
+
+	return collapseDoubles(s)
+}
+
+// collapseDoubles reduces repeated letters: "matthieu" -> "matieu".
+func collapseDoubles(s string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range s {
+		if r != prev {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package contact
+
+import "testing"
+
+func TestPhoneticKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Empty", "", ""},
+		{"Ph and double letters", "Phillipe", "filip"},
+		{"Silent h and au", "Gauthier", "gotie"},
+		{"Nasal in", "Martin", "mart1"},
+		{"Nasal on and silent t", "Dupont", "dup3"},
+		{"Hard ch", "Christophe", "kristof"},
+		{"Soft g", "Jérôme", "jerom"},
+		{"Gu before i", "Guillaume", "gilom"},
+		{"Cedilla", "François", "fr2soa"},
+		{"Compound name", "Jean-Marc", "j2 mark"},
+		{"Eau", "Rousseau", "ruzo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PhoneticKey(tt.input); got != tt.expected {
+				t.Errorf("PhoneticKey(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPhoneticKeyMatches(t *testing.T) {
+	tests := []struct {
+		name1    string
+		name2    string
+		expected bool
+	}{
+		// Spellings seen between the intranet and the families' address books
+		{"Phillipe", "Filip", true},
+		{"Philippe", "Philipe", true},
+		{"Gauthier", "Gautier", true},
+		{"Mathieu", "Matthieu", true},
+		{"Christophe", "Kristof", true},
+		{"Catherine", "Katherine", true},
+		{"Sylvie", "Silvie", true},
+		{"Thomas", "Tomas", true},
+		{"Chloé", "Cloé", true},
+		{"Clément", "Klément", true},
+		{"Laurence", "Lorence", true},
+		{"Vincent", "Vinsent", true},
+		{"Julien", "Jullien", true},
+		{"Lucas", "Luka", true},
+		{"Lefebvre", "Lefèvre", true},
+		{"Dupont", "Dupond", true},
+		{"Rousseau", "Rousso", true},
+		{"Bertrand", "Bertran", true},
+		{"Raphaël", "Rafael", true},
+		{"Georges", "Jorge", true},
+
+		// Different first names must stay apart
+		{"Martin", "Martine", false},
+		{"Louis", "Louise", false},
+		{"Jean", "Jeanne", false},
+		{"Denis", "Denise", false},
+		{"Fabien", "Fabian", false},
+		{"Léo", "Léa", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name1+"/"+tt.name2, func(t *testing.T) {
+			key1, key2 := PhoneticKey(tt.name1), PhoneticKey(tt.name2)
+			if got := key1 != "" && key1 == key2; got != tt.expected {
+				t.Errorf("PhoneticKey(%q) == PhoneticKey(%q) = %t, want %t (keys %q, %q)",
+					tt.name1, tt.name2, got, tt.expected, key1, key2)
+			}
+		})
+	}
+}
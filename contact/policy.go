@@ -0,0 +1,81 @@
+package contact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// FieldLabels identifies the labels in a merge policy. Labels are always
+// merged together, unless their source is ignored.
+const FieldLabels Field = "labels"
+
+// Rule is how the values of a source are merged for a field.
+type Rule string
+
+const (
+	// RuleAuthoritative values replace the values of other sources.
+	RuleAuthoritative Rule = "authoritative"
+	// RuleFillOnly values only fill empty fields.
+	RuleFillOnly Rule = "fill-only"
+	// RuleIgnore values are never merged into a contact from another source.
+	RuleIgnore Rule = "ignore"
+)
+
+// level orders the rules: the value of the highest level wins, the default
+// being the timestamp rule of MergeContact.
+func (r Rule) level() int {
+	switch r {
+	case RuleAuthoritative:
+		return 3
+	case RuleFillOnly:
+		return 1
+	case RuleIgnore:
+		return 0
+	}
+	return 2
+}
+
+// MergePolicy gives the rule of each source (see Source.Name) for each field.
+// Besides the fields, "email", "phone" and "address" apply to all the emails,
+// phones and addresses.
+// Fields without a rule follow the default behavior of MergeContact.
+type MergePolicy map[string]map[Field]Rule
+
+// rule returns the rule of a source for a field.
+func (p MergePolicy) rule(source string, f Field) Rule {
+	rules := p[source]
+	if r, ok := rules[f]; ok {
+		return r
+	}
+	group, _, _ := strings.Cut(string(f), ":")
+	return rules[Field(group)]
+}
+
+// level returns the level of the rule of the source of field f of c.
+func (p MergePolicy) level(c *Contact, f Field) int {
+	return p.rule(c.Provenance[f].Name, f).level()
+}
+
+// LoadMergePolicy reads a JSON merge policy, an object of rules by field by
+// source name.
+func LoadMergePolicy(r io.Reader) (MergePolicy, error) {
+	var p MergePolicy
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, fmt.Errorf("error decoding merge policy: %v", err)
+	}
+
+	for source, rules := range p {
+		for f, r := range rules {
+			if !slices.Contains(Fields, f) && f != FieldLabels && f != "email" && f != "phone" && f != "address" {
+				return nil, fmt.Errorf("merge policy of %q: unknown field %q", source, f)
+			}
+			if r != RuleAuthoritative && r != RuleFillOnly && r != RuleIgnore {
+				return nil, fmt.Errorf("merge policy of %q: unknown rule %q for %s, want authoritative, fill-only or ignore", source, r, f)
+			}
+		}
+	}
+	return p, nil
+}
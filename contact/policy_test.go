@@ -0,0 +1,148 @@
+package contact
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMergePolicy(t *testing.T) {
+	got, err := LoadMergePolicy(strings.NewReader(`{
+		"intranet": {"member_code": "authoritative", "birthday": "authoritative", "labels": "authoritative"},
+		"gmail": {"phone": "authoritative", "email:Personal": "fill-only", "address:work": "ignore"}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadMergePolicy() error = %v", err)
+	}
+	want := MergePolicy{
+		"intranet": {FieldMemberCode: RuleAuthoritative, FieldBirthday: RuleAuthoritative, FieldLabels: RuleAuthoritative},
+		"gmail":    {"phone": RuleAuthoritative, EmailField(EmailPersonal): RuleFillOnly, AddressField(AddressWork): RuleIgnore},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadMergePolicy() = %+v, want %+v", got, want)
+	}
+
+	for _, input := range []string{
+		`{"gmail": {"nickname": "authoritative"}}`,
+		`{"gmail": {"address": "always"}}`,
+		`["gmail"]`,
+	} {
+		if _, err := LoadMergePolicy(strings.NewReader(input)); err == nil {
+			t.Errorf("LoadMergePolicy(%s), want error", input)
+		}
+	}
+}
+
+func TestMergeContactWithPolicy(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	birthday := time.Date(2012, 5, 3, 0, 0, 0, 0, time.UTC)
+	otherBirthday := time.Date(2012, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	policy := MergePolicy{
+		"intranet": {FieldBirthday: RuleAuthoritative, FieldPosition: RuleAuthoritative},
+		"gmail":    {"phone": RuleAuthoritative, "address": RuleFillOnly, AddressField(AddressWork): RuleIgnore, FieldLabels: RuleIgnore},
+	}
+
+	// The intranet export is newer than the Gmail edits
+	intranet := Contact{
+		Birthday:  &birthday,
+		Addresses: homeAddress(Address{City: "Paris"}),
+		Position:  "Chef",
+		Phones:    map[PhoneType]string{PhoneMobile1: "0611111111"},
+		Labels:    []Label{LabelAdherent},
+		UpdatedAt: &newer,
+	}
+	intranet.SetSource(Source{Name: "intranet"})
+	gmail := Contact{
+		Birthday: &otherBirthday,
+		Addresses: map[AddressType]Address{
+			AddressHome:  {City: "Lyon"},
+			AddressWork:  {City: "Lyon", Country: "France"},
+			AddressOther: {ZipCode: "78660", City: "Jambville"},
+		},
+		Position:  "Parent",
+		Phones:    map[PhoneType]string{PhoneMobile1: "0622222222", PhoneHome: "0144444444"},
+		Labels:    []Label{"Amis"},
+		UpdatedAt: &older,
+	}
+	gmail.SetSource(Source{Name: "gmail"})
+
+	tests := []struct {
+		name        string
+		destination Contact
+		source      Contact
+	}{
+		{"Gmail merged into intranet", intranet, gmail},
+		{"Intranet merged into Gmail", gmail, intranet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := copyContact(&tt.destination)
+			merged.MergeContactWithPolicy(&tt.source, policy)
+
+			if !merged.Birthday.Equal(birthday) {
+				t.Errorf("Birthday = %v, want the authoritative intranet one", merged.Birthday)
+			}
+			if merged.Position != "Chef" {
+				t.Errorf("Position = %q, want the authoritative intranet one", merged.Position)
+			}
+			if got := merged.GetPhone(PhoneMobile1); got != "0622222222" {
+				t.Errorf("Mobile = %q, want the authoritative Gmail one", got)
+			}
+			if got := merged.GetPhone(PhoneHome); got != "0144444444" {
+				t.Errorf("Home phone = %q, want it filled by Gmail", got)
+			}
+			if got := merged.GetAddress(AddressHome).City; got != "Paris" {
+				t.Errorf("Home city = %q, want the intranet one over the fill-only Gmail one", got)
+			}
+			if got := merged.GetAddress(AddressOther).City; got != "Jambville" {
+				t.Errorf("Other city = %q, want it filled by Gmail", got)
+			}
+		})
+	}
+
+	// Ignored values are never merged into a contact from another source
+	merged := copyContact(&intranet)
+	merged.MergeContactWithPolicy(&gmail, policy)
+	if got := merged.GetAddress(AddressWork); !got.IsZero() {
+		t.Errorf("Work address = %q, want the ignored Gmail one left out", got)
+	}
+	if !reflect.DeepEqual(merged.Labels, []Label{LabelAdherent}) {
+		t.Errorf("Labels = %v, want the ignored Gmail ones left out", merged.Labels)
+	}
+
+	// ...and an ignored value is replaced by the value of another source
+	merged = copyContact(&gmail)
+	work := Contact{Addresses: map[AddressType]Address{AddressWork: {Country: "Belgique"}}}
+	work.SetSource(Source{Name: "intranet"})
+	merged.MergeContactWithPolicy(&work, policy)
+	if got := merged.GetAddress(AddressWork).Country; got != "Belgique" {
+		t.Errorf("Work country = %q, want the ignored Gmail one replaced", got)
+	}
+}
+
+func TestMergeContactWithPolicyConflicts(t *testing.T) {
+	newer := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	intranet := Source{Name: "intranet"}
+	gmail := Source{Name: "gmail"}
+	policy := MergePolicy{"gmail": {"phone": RuleAuthoritative}}
+
+	destination := Contact{Phones: map[PhoneType]string{PhoneMobile1: "0611111111"}, UpdatedAt: &newer}
+	destination.SetSource(intranet)
+	source := Contact{Phones: map[PhoneType]string{PhoneMobile1: "0622222222"}}
+	source.SetSource(gmail)
+
+	want := []Conflict{{
+		Field:           PhoneField(PhoneMobile1),
+		Kept:            "0622222222",
+		Discarded:       "0611111111",
+		KeptSource:      gmail,
+		DiscardedSource: intranet,
+	}}
+	if got := destination.MergeContactWithPolicy(&source, policy); !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeContactWithPolicy() conflicts = %+v, want %+v", got, want)
+	}
+}
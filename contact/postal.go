@@ -0,0 +1,91 @@
+package contact
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/tinque/totem/address"
+)
+
+// AddressIssue is an address whose postal code and city are invalid, with
+// the corrections found.
+type AddressIssue struct {
+	Contact ContactRef
+	Type    AddressType
+	Address Address
+	Problem address.Problem
+	ZipCode string // Suggested postal code, if any
+	City    string // Suggested city, if any
+}
+
+// CheckAddresses validates the postal code and city of the French addresses
+// of the contacts against a postal code dataset, read from src. The postal
+// code and city of a valid address are normalized as by the dataset (see
+// address.PostalCodes.Check) and the address takes src as provenance, with
+// the time of its former source: the dataset only changes its spelling. The
+// invalid addresses are left as is and returned.
+func CheckAddresses(contacts []Contact, codes *address.PostalCodes, src Source) []AddressIssue {
+	var issues []AddressIssue
+	for i := range contacts {
+		c := &contacts[i]
+		for _, at := range AddressTypes {
+			a := c.GetAddress(at)
+			if a.IsZero() || !isFrance(a.Country) {
+				continue
+			}
+			check := codes.Check(a.ZipCode, a.City)
+			if check.Valid() {
+				normalized := a
+				if check.ZipCode != "" {
+					normalized.ZipCode = check.ZipCode
+				}
+				if check.City != "" {
+					normalized.City = check.City
+				}
+				if normalized != a {
+					c.SetAddress(at, normalized)
+					src.Time = c.Provenance[AddressField(at)].Time
+					c.setProvenance(AddressField(at), src)
+				}
+				continue
+			}
+			issues = append(issues, AddressIssue{
+				Contact: newContactRef(c),
+				Type:    at,
+				Address: a,
+				Problem: check.Problem,
+				ZipCode: check.ZipCode,
+				City:    check.City,
+			})
+		}
+	}
+	return issues
+}
+
+// isFrance reports whether an address country is France, the default.
+func isFrance(country string) bool {
+	return country == "" || NormalizeName(country) == "france"
+}
+
+// addressIssuesHeader is the header of the invalid addresses report.
+var addressIssuesHeader = []string{"member_code", "first_name", "last_name", "type", "address", "problem", "suggested_zip_code", "suggested_city"}
+
+// WriteAddressIssuesCSV writes a report with one line per invalid address.
+func WriteAddressIssuesCSV(w io.Writer, issues []AddressIssue) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(addressIssuesHeader); err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		record := []string{
+			issue.Contact.MemberCode, issue.Contact.FirstName, issue.Contact.LastName,
+			string(issue.Type), issue.Address.String(), string(issue.Problem),
+			issue.ZipCode, issue.City,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
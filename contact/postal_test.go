@@ -0,0 +1,67 @@
+package contact
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tinque/totem/address"
+)
+
+func TestCheckAddresses(t *testing.T) {
+	codes, err := address.LoadPostalCodes(strings.NewReader("Code_postal;Nom_de_la_commune;Libellé_d_acheminement\n75011;PARIS 11;PARIS\n69003;LYON 03;LYON\n01000;BOURG EN BRESSE;BOURG EN BRESSE\n"))
+	if err != nil {
+		t.Fatalf("LoadPostalCodes() error = %v", err)
+	}
+	contacts := []Contact{
+		{MemberCode: "1", FirstName: "Louis", Addresses: homeAddress(Address{Street: "3 rue des Lilas", ZipCode: "75011", City: "PARIS"})},
+		{FirstName: "Marie", Addresses: map[AddressType]Address{
+			AddressHome: {ZipCode: "75O11", City: "Paris"},
+			AddressWork: {ZipCode: "69003", City: "Lyon 3e"},
+		}},
+		{FirstName: "Paul", Addresses: homeAddress(Address{ZipCode: "1000", City: "Bruxelles", Country: "Belgique"})},
+		{FirstName: "Léa", Addresses: homeAddress(Address{ZipCode: "1000", City: "Bourg en Bresse"})},
+	}
+	at := time.Date(2025, 9, 16, 10, 0, 0, 0, time.UTC)
+	contacts[0].SetSource(Source{Name: "intranet", Row: 1, Time: at})
+
+	got := CheckAddresses(contacts, codes, Source{Name: "postal-codes", File: "codes.csv"})
+	want := []AddressIssue{{
+		Contact: ContactRef{FirstName: "Marie"},
+		Type:    AddressHome,
+		Address: Address{ZipCode: "75O11", City: "Paris"},
+		Problem: address.ProblemZipCodeFormat,
+		ZipCode: "75011",
+		City:    "Paris",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckAddresses() = %+v, want %+v", got, want)
+	}
+	if city := contacts[0].GetAddress(AddressHome).City; city != "Paris" {
+		t.Errorf("City = %q, want it normalized", city)
+	}
+	if src, want := contacts[0].Provenance[AddressField(AddressHome)], (Source{Name: "postal-codes", File: "codes.csv", Time: at}); src != want {
+		t.Errorf("Provenance = %+v, want %+v", src, want)
+	}
+	if src := contacts[0].Provenance[FieldMemberCode]; src.Name != "intranet" {
+		t.Errorf("member code provenance = %+v, want it kept", src)
+	}
+	if city := contacts[1].GetAddress(AddressWork).City; city != "Lyon 3e" {
+		t.Errorf("City = %q, want it kept", city)
+	}
+	if zipCode := contacts[3].GetAddress(AddressHome).ZipCode; zipCode != "01000" {
+		t.Errorf("ZipCode = %q, want it padded", zipCode)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAddressIssuesCSV(&buf, got); err != nil {
+		t.Fatalf("WriteAddressIssuesCSV() error = %v", err)
+	}
+	wantCSV := "member_code,first_name,last_name,type,address,problem,suggested_zip_code,suggested_city\n" +
+		",Marie,,home,75O11 Paris,invalid postal code,75011,Paris\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteAddressIssuesCSV() = %q, want %q", buf.String(), wantCSV)
+	}
+}
@@ -0,0 +1,87 @@
+package contact
+
+import "strings"
+
+// mobilePhones are the phone types owned by a single person. Home and work
+// numbers are often shared by a household or a company.
+var mobilePhones = []PhoneType{PhoneMobile1, PhoneMobile2}
+
+// contactProfile caches the normalized forms of a contact used to detect
+// duplicates, which are costly to compute for every compared pair.
+type contactProfile struct {
+	first, last                 string
+	firstPhonetic, lastPhonetic string
+	emails                      []string // Normalized emails
+	mobiles                     []string // Normalized mobile phones
+	zipCodes                    []string // Zip codes of the addresses
+}
+
+// nameCache memoizes name normalization: in an export the same first and
+// last names come back for every member of a family.
+type nameCache struct {
+	normalized map[string]string
+	phonetic   map[string]string
+}
+
+func newNameCache() *nameCache {
+	return &nameCache{normalized: make(map[string]string), phonetic: make(map[string]string)}
+}
+
+func (nc *nameCache) contactProfile(c *Contact) contactProfile {
+	p := contactProfile{
+		first:         nc.normalize(c.FirstName),
+		last:          nc.normalize(c.LastName),
+		firstPhonetic: nc.phoneticKey(c.FirstName),
+		lastPhonetic:  nc.phoneticKey(c.LastName),
+	}
+
+	for _, email := range c.Emails {
+		if email = NormalizeEmail(email); email != "" {
+			p.emails = append(p.emails, email)
+		}
+	}
+
+	for _, pt := range mobilePhones {
+		if phone := NormalizePhone(c.GetPhone(pt)); phone != "" {
+			p.mobiles = append(p.mobiles, phone)
+		}
+	}
+
+	for _, at := range AddressTypes {
+		if zipCode := strings.TrimSpace(c.GetAddress(at).ZipCode); zipCode != "" {
+			p.zipCodes = append(p.zipCodes, zipCode)
+		}
+	}
+
+	return p
+}
+
+func (nc *nameCache) normalize(name string) string {
+	v, ok := nc.normalized[name]
+	if !ok {
+		v = NormalizeName(name)
+		nc.normalized[name] = v
+	}
+	return v
+}
+
+func (nc *nameCache) phoneticKey(name string) string {
+	v, ok := nc.phonetic[name]
+	if !ok {
+		v = PhoneticKey(name)
+		nc.phonetic[name] = v
+	}
+	return v
+}
+
+// shared returns a value found in both lists of normalized values.
+func shared(values1, values2 []string) (string, bool) {
+	for _, v1 := range values1 {
+		for _, v2 := range values2 {
+			if v1 == v2 {
+				return v1, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,171 @@
+package contact
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field identifies a contact field. Emails, phones and addresses are
+// identified by type (see EmailField, PhoneField and AddressField).
+type Field string
+
+const (
+	FieldMemberCode Field = "member_code"
+	FieldFirstName  Field = "first_name"
+	FieldLastName   Field = "last_name"
+	FieldBirthday   Field = "birthday"
+	FieldPosition   Field = "position"
+)
+
+func EmailField(et EmailType) Field {
+	return Field("email:" + string(et))
+}
+
+func PhoneField(pt PhoneType) Field {
+	return Field("phone:" + string(pt))
+}
+
+func AddressField(at AddressType) Field {
+	return Field("address:" + string(at))
+}
+
+// Fields lists the contact fields in display order.
+var Fields = []Field{
+	FieldMemberCode,
+	FieldFirstName,
+	FieldLastName,
+	EmailField(EmailPersonal),
+	EmailField(EmailDedicatedSGDF),
+	PhoneField(PhoneMobile1),
+	PhoneField(PhoneMobile2),
+	PhoneField(PhoneHome),
+	PhoneField(PhoneWork),
+	FieldBirthday,
+	AddressField(AddressHome),
+	AddressField(AddressWork),
+	AddressField(AddressOther),
+	FieldPosition,
+}
+
+// FieldValue returns the value of a field as text, birthdays being formatted
+// as 2006-01-02 and addresses on a single line (see Address.String).
+func (c *Contact) FieldValue(f Field) string {
+	switch f {
+	case FieldMemberCode:
+		return c.MemberCode
+	case FieldFirstName:
+		return c.FirstName
+	case FieldLastName:
+		return c.LastName
+	case FieldBirthday:
+		if c.Birthday == nil {
+			return ""
+		}
+		return c.Birthday.Format("2006-01-02")
+	case FieldPosition:
+		return c.Position
+	}
+	if et, ok := strings.CutPrefix(string(f), "email:"); ok {
+		return c.GetEmail(EmailType(et))
+	}
+	if pt, ok := strings.CutPrefix(string(f), "phone:"); ok {
+		return c.GetPhone(PhoneType(pt))
+	}
+	if at, ok := strings.CutPrefix(string(f), "address:"); ok {
+		return c.GetAddress(AddressType(at)).String()
+	}
+	return ""
+}
+
+// Source tells where the value of a field comes from.
+type Source struct {
+	Name string    `json:"name"`           // Kind of source, e.g. intranet or gmail
+	File string    `json:"file,omitempty"` // Path of the source file
+	Row  int       `json:"row,omitempty"`  // Number of the data row in the file, from 1, the header excluded
+	Time time.Time `json:"time"`           // When the source data was produced
+}
+
+func (s Source) String() string {
+	var details []string
+	if s.File != "" {
+		details = append(details, s.File)
+	}
+	if s.Row > 0 {
+		details = append(details, "row "+strconv.Itoa(s.Row))
+	}
+	if !s.Time.IsZero() {
+		details = append(details, s.Time.Format("2006-01-02 15:04:05"))
+	}
+	if len(details) == 0 {
+		return s.Name
+	}
+	return s.Name + " (" + strings.Join(details, ", ") + ")"
+}
+
+// SetSource records src as the provenance of every non-empty field, and of
+// the labels if any.
+func (c *Contact) SetSource(src Source) {
+	for _, f := range Fields {
+		if c.FieldValue(f) != "" {
+			c.setProvenance(f, src)
+		}
+	}
+	if len(c.Labels) > 0 {
+		c.setProvenance(FieldLabels, src)
+	}
+}
+
+func (c *Contact) setProvenance(f Field, src Source) {
+	if c.Provenance == nil {
+		c.Provenance = make(map[Field]Source)
+	}
+	c.Provenance[f] = src
+}
+
+// takeProvenance records that field f now holds the value of source.
+func (c *Contact) takeProvenance(source *Contact, f Field) {
+	src, ok := source.Provenance[f]
+	if !ok {
+		delete(c.Provenance, f) // The value is of unknown origin
+		return
+	}
+	c.setProvenance(f, src)
+}
+
+// provenanceHeader is the header of the provenance report.
+var provenanceHeader = []string{"member_code", "first_name", "last_name", "field", "value", "source", "file", "row", "time"}
+
+// WriteProvenanceCSV writes a report with one line per field of each contact
+// whose provenance is known.
+func WriteProvenanceCSV(w io.Writer, contacts []Contact) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(provenanceHeader); err != nil {
+		return err
+	}
+	for i := range contacts {
+		c := &contacts[i]
+		for _, f := range Fields {
+			src, ok := c.Provenance[f]
+			if !ok {
+				continue
+			}
+			row := ""
+			if src.Row > 0 {
+				row = strconv.Itoa(src.Row)
+			}
+			t := ""
+			if !src.Time.IsZero() {
+				t = src.Time.Format(time.RFC3339)
+			}
+			record := []string{c.MemberCode, c.FirstName, c.LastName, string(f), c.FieldValue(f), src.Name, src.File, row, t}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
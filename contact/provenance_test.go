@@ -0,0 +1,139 @@
+package contact
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldValue(t *testing.T) {
+	birthday := time.Date(2012, 5, 3, 0, 0, 0, 0, time.UTC)
+	c := &Contact{
+		FirstName: "Léo",
+		Birthday:  &birthday,
+		Emails:    map[EmailType]string{EmailDedicatedSGDF: "leo@sgdf.fr"},
+		Phones:    map[PhoneType]string{PhoneMobile2: "0612345678"},
+	}
+
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{FieldFirstName, "Léo"},
+		{FieldLastName, ""},
+		{FieldBirthday, "2012-05-03"},
+		{EmailField(EmailDedicatedSGDF), "leo@sgdf.fr"},
+		{EmailField(EmailPersonal), ""},
+		{PhoneField(PhoneMobile2), "0612345678"},
+		{Field("unknown"), ""},
+	}
+	for _, tt := range tests {
+		if got := c.FieldValue(tt.field); got != tt.want {
+			t.Errorf("FieldValue(%q) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestSetSource(t *testing.T) {
+	src := Source{Name: "intranet", File: "export.xls", Row: 3}
+	c := &Contact{FirstName: "Léo", Phones: map[PhoneType]string{PhoneMobile1: "0612345678"}}
+	c.SetSource(src)
+
+	want := map[Field]Source{FieldFirstName: src, PhoneField(PhoneMobile1): src}
+	if !reflect.DeepEqual(c.Provenance, want) {
+		t.Errorf("Provenance = %+v, want %+v", c.Provenance, want)
+	}
+
+	empty := &Contact{}
+	empty.SetSource(src)
+	if empty.Provenance != nil {
+		t.Errorf("Provenance of an empty contact = %+v, want nil", empty.Provenance)
+	}
+}
+
+func TestMergeContactProvenance(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	intranet := Source{Name: "intranet", File: "export.xls", Row: 1}
+	gmail := Source{Name: "gmail", File: "contacts.csv", Row: 7}
+
+	destination := &Contact{
+		FirstName: "John",
+		Addresses: homeAddress(Address{City: "Paris"}),
+		Phones:    map[PhoneType]string{PhoneMobile1: "0611111111"},
+		UpdatedAt: &older,
+	}
+	destination.SetSource(intranet)
+
+	source := &Contact{
+		FirstName: "John",
+		Addresses: homeAddress(Address{ZipCode: "69001", City: "Lyon"}),
+		Phones:    map[PhoneType]string{PhoneMobile1: "0622222222", PhoneHome: "0144444444"},
+		UpdatedAt: &newer,
+	}
+	source.SetSource(gmail)
+	source.SetAddress(AddressWork, Address{City: "Lyon"}) // Of unknown origin
+
+	merged := MergeContacts(destination, source)
+
+	want := map[Field]Source{
+		FieldFirstName:            gmail,
+		AddressField(AddressHome): gmail,
+		PhoneField(PhoneMobile1):  gmail,
+		PhoneField(PhoneHome):     gmail,
+	}
+	if !reflect.DeepEqual(merged.Provenance, want) {
+		t.Errorf("Provenance = %+v, want %+v", merged.Provenance, want)
+	}
+
+	// The destination is copied, with its provenance
+	if got := destination.Provenance[AddressField(AddressHome)]; got != intranet {
+		t.Errorf("destination provenance modified: %+v", destination.Provenance)
+	}
+	copied := copyContact(destination)
+	if !reflect.DeepEqual(copied.Provenance, destination.Provenance) {
+		t.Errorf("copyContact() provenance = %+v, want %+v", copied.Provenance, destination.Provenance)
+	}
+	copied.Provenance[AddressField(AddressHome)] = gmail
+	if destination.Provenance[AddressField(AddressHome)] != intranet {
+		t.Error("copyContact() shares the provenance map")
+	}
+}
+
+func TestWriteProvenanceCSV(t *testing.T) {
+	contacts := []Contact{
+		{
+			MemberCode: "123",
+			FirstName:  "John",
+			LastName:   "Doe",
+			Phones:     map[PhoneType]string{PhoneMobile1: "0612345678"},
+			Provenance: map[Field]Source{
+				PhoneField(PhoneMobile1): {Name: "gmail", File: "contacts.csv", Row: 7, Time: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)},
+				FieldFirstName:           {Name: "intranet"},
+			},
+		},
+		{FirstName: "Jane"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteProvenanceCSV(&buf, contacts); err != nil {
+		t.Fatalf("WriteProvenanceCSV() error = %v", err)
+	}
+	want := "member_code,first_name,last_name,field,value,source,file,row,time\n" +
+		"123,John,Doe,first_name,John,intranet,,,\n" +
+		"123,John,Doe,phone:mobile1,0612345678,gmail,contacts.csv,7,2025-09-01T10:00:00Z\n"
+	if buf.String() != want {
+		t.Errorf("WriteProvenanceCSV() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSourceString(t *testing.T) {
+	src := Source{Name: "gmail", File: "contacts.csv", Row: 7, Time: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}
+	if got, want := src.String(), "gmail (contacts.csv, row 7, 2025-09-01 10:00:00)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := (Source{Name: "intranet"}).String(); got != "intranet" {
+		t.Errorf("String() = %q, want intranet", got)
+	}
+}
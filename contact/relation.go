@@ -0,0 +1,107 @@
+package contact
+
+import (
+	"slices"
+	"strings"
+)
+
+// RelationType is the role of a related person, e.g. a contact's child.
+type RelationType string
+
+const (
+	RelationChild  RelationType = "child"
+	RelationParent RelationType = "parent"
+	RelationSpouse RelationType = "spouse"
+)
+
+// FieldRelations identifies the relations in a FieldChange.
+const FieldRelations Field = "relations"
+
+// Relation links a contact to a related person, named as in Gmail. Key
+// identifies the related contact when known (see Contact.Key), so that the
+// name can be refreshed once the contacts are merged.
+type Relation struct {
+	Type RelationType `json:"type"`
+	Name string       `json:"name"`
+	Key  string       `json:"key,omitempty"`
+}
+
+// FullName returns the first and last names of a contact.
+func (c *Contact) FullName() string {
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+// AddRelation records that other is related to the contact, e.g. its child.
+func (c *Contact) AddRelation(t RelationType, other *Contact) {
+	c.addRelation(Relation{Type: t, Name: other.FullName(), Key: other.Key()})
+}
+
+// addRelation adds a relation unless the contact already has it, with the
+// same key or, when a key is missing, the same name.
+func (c *Contact) addRelation(r Relation) {
+	if r.Name == "" && r.Key == "" {
+		return
+	}
+	for i, e := range c.Relations {
+		if e.Type != r.Type {
+			continue
+		}
+		if e.Key != "" && r.Key != "" {
+			if e.Key == r.Key {
+				return
+			}
+			continue
+		}
+		if sameValue(FieldRelations, e.Name, r.Name) {
+			if e.Key == "" {
+				c.Relations[i].Key = r.Key
+			}
+			return
+		}
+	}
+	c.Relations = append(c.Relations, r)
+}
+
+// relationStrings returns the relations as sorted "type: name" strings.
+func (c *Contact) relationStrings() []string {
+	strs := make([]string, len(c.Relations))
+	for i, r := range c.Relations {
+		strs[i] = string(r.Type) + ": " + r.Name
+	}
+	slices.Sort(strs)
+	return strs
+}
+
+// ResolveRelations refreshes the names of the related contacts found by key
+// among contacts, once merged: a relation recorded before the merge may name
+// a contact whose name was completed or corrected since.
+func ResolveRelations(contacts []Contact) {
+	index := relationIndex(contacts)
+	for i := range contacts {
+		for j, r := range contacts[i].Relations {
+			if k, ok := index[r.Key]; ok && contacts[k].FullName() != "" {
+				contacts[i].Relations[j].Name = contacts[k].FullName()
+			}
+		}
+	}
+}
+
+// relationIndex returns the indices of the contacts by the keys a relation
+// may hold: IDs, member codes and normalized emails.
+func relationIndex(contacts []Contact) map[string]int {
+	index := make(map[string]int)
+	for i := range contacts {
+		c := &contacts[i]
+		for _, key := range []string{c.ID, c.MemberCode} {
+			if key != "" {
+				index[key] = i
+			}
+		}
+		for _, email := range c.Emails {
+			if email = NormalizeEmail(email); email != "" {
+				index[email] = i
+			}
+		}
+	}
+	return index
+}
@@ -0,0 +1,66 @@
+package contact
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddRelation(t *testing.T) {
+	child := Contact{MemberCode: "123", FirstName: "Louis", LastName: "Martin"}
+	mother := Contact{FirstName: "Marie", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "Marie@Example.com"}}
+
+	mother.AddRelation(RelationChild, &child)
+	mother.AddRelation(RelationChild, &child)
+	child.AddRelation(RelationParent, &mother)
+
+	if want := []Relation{{Type: RelationChild, Name: "Louis Martin", Key: "123"}}; !reflect.DeepEqual(mother.Relations, want) {
+		t.Errorf("mother relations = %+v, want %+v", mother.Relations, want)
+	}
+	if want := []Relation{{Type: RelationParent, Name: "Marie Martin", Key: "marie@example.com"}}; !reflect.DeepEqual(child.Relations, want) {
+		t.Errorf("child relations = %+v, want %+v", child.Relations, want)
+	}
+}
+
+func TestMergeContactRelations(t *testing.T) {
+	// The same parent, from two rows of the intranet export and from Gmail
+	intranet := Contact{FirstName: "Marie", LastName: "Martin", Relations: []Relation{{Type: RelationChild, Name: "Louis Martin", Key: "123"}}}
+	intranet2 := Contact{FirstName: "Marie", LastName: "Martin", Relations: []Relation{{Type: RelationChild, Name: "Léa Martin", Key: "456"}}}
+	gmail := Contact{FirstName: "Marie", LastName: "Martin", Relations: []Relation{
+		{Type: RelationChild, Name: "louis martin"},
+		{Type: RelationSpouse, Name: "Pierre Martin"},
+	}}
+
+	merged := MergeContacts(&gmail, &intranet)
+	merged.MergeContact(&intranet2)
+
+	want := []Relation{
+		{Type: RelationChild, Name: "louis martin", Key: "123"},
+		{Type: RelationSpouse, Name: "Pierre Martin"},
+		{Type: RelationChild, Name: "Léa Martin", Key: "456"},
+	}
+	if !reflect.DeepEqual(merged.Relations, want) {
+		t.Errorf("merged relations = %+v, want %+v", merged.Relations, want)
+	}
+	if len(gmail.Relations) != 2 {
+		t.Errorf("MergeContacts() modified the destination relations: %+v", gmail.Relations)
+	}
+}
+
+func TestResolveRelations(t *testing.T) {
+	contacts := []Contact{
+		{MemberCode: "123", FirstName: "Louis", LastName: "Martin-Durand"},
+		{FirstName: "Marie", LastName: "Martin", Relations: []Relation{
+			{Type: RelationChild, Name: "Louis Martin", Key: "123"},
+			{Type: RelationSpouse, Name: "Pierre Martin"},
+		}},
+	}
+	ResolveRelations(contacts)
+
+	want := []Relation{
+		{Type: RelationChild, Name: "Louis Martin-Durand", Key: "123"},
+		{Type: RelationSpouse, Name: "Pierre Martin"},
+	}
+	if !reflect.DeepEqual(contacts[1].Relations, want) {
+		t.Errorf("relations = %+v, want %+v", contacts[1].Relations, want)
+	}
+}
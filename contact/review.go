@@ -0,0 +1,193 @@
+package contact
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Answer is a manual decision on an uncertain pair of contacts.
+type Answer string
+
+const (
+	AnswerMerge    Answer = "merge"
+	AnswerSeparate Answer = "separate"
+	AnswerAlways   Answer = "always" // Merge, and remember it as an override
+	AnswerNever    Answer = "never"  // Keep separate, and remember it as an override
+)
+
+// Reviewer asks for a decision on a pair of contacts the matcher is
+// uncertain about (see Match.Uncertain).
+type Reviewer interface {
+	Review(contact1, contact2 *Contact, match Match) (Answer, error)
+}
+
+// overrideKey returns the key identifying a contact in an override: its
+// member code, else its first email, else its ID.
+func overrideKey(c *Contact) string {
+	if c.MemberCode != "" {
+		return c.MemberCode
+	}
+	if email := NormalizeEmail(c.FirstEmail()); email != "" {
+		return email
+	}
+	return c.ID
+}
+
+// overrideKeys returns the keys of two contacts in an override. Contacts
+// sharing an email, such as twins with a family mailbox, are told apart by
+// their IDs.
+func overrideKeys(c1, c2 *Contact) (string, string) {
+	key1, key2 := overrideKey(c1), overrideKey(c2)
+	if key1 == key2 && c1.ID != "" && c2.ID != "" && c1.ID != c2.ID {
+		return c1.ID, c2.ID
+	}
+	return key1, key2
+}
+
+// review asks the reviewer about an uncertain pair and returns the decision
+// to take, or false when the pair is left to the matcher, as are the pairs
+// already decided by the clusters. Always and never answers become overrides
+// for the rest of the run and are recorded in Learned, provided both contacts
+// have a distinct key, else they are counted in Unrecorded.
+func (d *Deduplicator) review(contacts []Contact, uf *unionFind, i, j int, match Match) (Decision, bool) {
+	if d.Reviewer == nil || !match.Uncertain || d.ReviewErr != nil {
+		return "", false
+	}
+	// Nothing to ask when already merged, or kept apart by an override
+	if uf.find(i) == uf.find(j) || !uf.canUnion(i, j) {
+		return "", false
+	}
+
+	// The same pair comes back when deduplicating merged lists
+	pair := []string{newContactRef(&contacts[i]).String(), newContactRef(&contacts[j]).String()}
+	slices.Sort(pair)
+	pairKey := strings.Join(pair, "\n")
+
+	answer, ok := d.answers[pairKey]
+	if !ok {
+		var err error
+		answer, err = d.Reviewer.Review(&contacts[i], &contacts[j], match)
+		if err != nil {
+			d.ReviewErr = err
+			return "", false
+		}
+		if d.answers == nil {
+			d.answers = make(map[string]Answer)
+		}
+		d.answers[pairKey] = answer
+	}
+
+	switch answer {
+	case AnswerMerge:
+		return DecisionMerge, true
+	case AnswerAlways, AnswerNever:
+		key1, key2 := overrideKeys(&contacts[i], &contacts[j])
+		action, decision := OverrideAlways, DecisionAlways
+		if answer == AnswerNever {
+			action, decision = OverrideNever, DecisionNever
+		}
+		if !ok && (key1 == "" || key2 == "" || key1 == key2) {
+			d.Unrecorded++
+		} else if !ok {
+			o := Override{Action: action, Key1: key1, Key2: key2}
+			d.Overrides = append(d.Overrides, o)
+			d.Learned = append(d.Learned, o)
+			if action == OverrideNever {
+				uf.forbid(i, len(d.Overrides)-1, 1)
+				uf.forbid(j, len(d.Overrides)-1, 2)
+			}
+		}
+		return decision, true
+	default:
+		return DecisionSeparate, true
+	}
+}
+
+// TerminalReviewer shows both contacts side by side and reads the answer
+// from the terminal.
+type TerminalReviewer struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func NewTerminalReviewer(in io.Reader, out io.Writer) *TerminalReviewer {
+	return &TerminalReviewer{in: bufio.NewScanner(in), out: out}
+}
+
+// reviewFields are the contact fields shown side by side.
+var reviewFields = []struct {
+	name  string
+	value func(c *Contact) string
+}{
+	{"Member code", func(c *Contact) string { return c.MemberCode }},
+	{"First name", func(c *Contact) string { return c.FirstName }},
+	{"Last name", func(c *Contact) string { return c.LastName }},
+	{"Email", func(c *Contact) string { return c.GetEmail(EmailPersonal) }},
+	{"Email SGDF", func(c *Contact) string { return c.GetEmail(EmailDedicatedSGDF) }},
+	{"Mobile 1", func(c *Contact) string { return c.GetPhone(PhoneMobile1) }},
+	{"Mobile 2", func(c *Contact) string { return c.GetPhone(PhoneMobile2) }},
+	{"Home phone", func(c *Contact) string { return c.GetPhone(PhoneHome) }},
+	{"Work phone", func(c *Contact) string { return c.GetPhone(PhoneWork) }},
+	{"Birthday", func(c *Contact) string {
+		if c.Birthday == nil {
+			return ""
+		}
+		return c.Birthday.Format("02/01/2006")
+	}},
+	{"Home address", func(c *Contact) string { return c.GetAddress(AddressHome).String() }},
+	{"Work address", func(c *Contact) string { return c.GetAddress(AddressWork).String() }},
+	{"Other address", func(c *Contact) string { return c.GetAddress(AddressOther).String() }},
+	{"Position", func(c *Contact) string { return c.Position }},
+	{"Labels", func(c *Contact) string { return strings.Join(c.LabelsAsStrings(), ", ") }},
+}
+
+// Review prints both contacts, differing fields being marked with a star,
+// with the evidence of the match, and asks until a valid answer is read.
+func (tr *TerminalReviewer) Review(contact1, contact2 *Contact, match Match) (Answer, error) {
+	tw := tabwriter.NewWriter(tr.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\n\tContact 1\tContact 2\t")
+	for _, f := range reviewFields {
+		v1, v2 := f.value(contact1), f.value(contact2)
+		if v1 == "" && v2 == "" {
+			continue
+		}
+		mark := ""
+		if v1 != v2 {
+			mark = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.name, v1, v2, mark)
+	}
+	if err := tw.Flush(); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(tr.out, "Score %g:", match.Score)
+	for _, e := range match.Evidence {
+		fmt.Fprintf(tr.out, " %s %+g", e.Signal, e.Weight)
+	}
+	fmt.Fprintln(tr.out)
+
+	for {
+		fmt.Fprint(tr.out, "[m]erge, [k]eep separate, [a]lways merge, [n]ever merge? ")
+		if !tr.in.Scan() {
+			if err := tr.in.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		switch strings.ToLower(strings.TrimSpace(tr.in.Text())) {
+		case "m", "merge":
+			return AnswerMerge, nil
+		case "k", "keep":
+			return AnswerSeparate, nil
+		case "a", "always":
+			return AnswerAlways, nil
+		case "n", "never":
+			return AnswerNever, nil
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package contact
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedReviewer answers from a list, then fails with io.EOF.
+type scriptedReviewer struct {
+	answers []Answer
+	asked   int
+}
+
+func (sr *scriptedReviewer) Review(contact1, contact2 *Contact, match Match) (Answer, error) {
+	if sr.asked >= len(sr.answers) {
+		return "", io.EOF
+	}
+	sr.asked++
+	return sr.answers[sr.asked-1], nil
+}
+
+func TestDeduplicatorReview(t *testing.T) {
+	birthday := time.Date(1980, 4, 2, 0, 0, 0, 0, time.UTC)
+	otherBirthday := time.Date(2010, 6, 9, 0, 0, 0, 0, time.UTC)
+	// Father and son: same name, different birthdays, uncertain with a
+	// birthday mismatch penalty
+	cfg := DefaultMatcherConfig()
+	cfg.Weights.BirthdayMismatch = -1
+	contacts := []Contact{
+		{FirstName: "Louis", LastName: "Martin", Birthday: &birthday, Emails: map[EmailType]string{EmailPersonal: "louis@example.com"}},
+		{FirstName: "Louis", LastName: "Martin", Birthday: &otherBirthday, Emails: map[EmailType]string{EmailPersonal: "junior@example.com"}},
+	}
+
+	tests := []struct {
+		name    string
+		answers []Answer
+		want    int
+		learned []Override
+	}{
+		{name: "Merge", answers: []Answer{AnswerMerge}, want: 1},
+		{name: "Keep separate", answers: []Answer{AnswerSeparate}, want: 2},
+		{
+			name:    "Always",
+			answers: []Answer{AnswerAlways},
+			want:    1,
+			learned: []Override{{Action: OverrideAlways, Key1: "louis@example.com", Key2: "junior@example.com"}},
+		},
+		{
+			name:    "Never",
+			answers: []Answer{AnswerNever},
+			want:    2,
+			learned: []Override{{Action: OverrideNever, Key1: "louis@example.com", Key2: "junior@example.com"}},
+		},
+		{name: "No answer falls back to the matcher", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deduplicator{Matcher: NewWeightedMatcher(cfg), Reviewer: &scriptedReviewer{answers: tt.answers}}
+			if got := d.Deduplicate(contacts); len(got) != tt.want {
+				t.Errorf("Deduplicate() returned %d contacts, want %d", len(got), tt.want)
+			}
+			if len(d.Learned) != len(tt.learned) || (len(tt.learned) > 0 && d.Learned[0] != tt.learned[0]) {
+				t.Errorf("Learned = %+v, want %+v", d.Learned, tt.learned)
+			}
+			if (tt.answers == nil) != errors.Is(d.ReviewErr, io.EOF) {
+				t.Errorf("ReviewErr = %v", d.ReviewErr)
+			}
+		})
+	}
+}
+
+func TestDeduplicatorReviewOnlyUncertain(t *testing.T) {
+	contacts := []Contact{
+		{FirstName: "John", LastName: "Doe"},
+		{FirstName: "John", LastName: "Doe"},
+		{FirstName: "Jane", LastName: "Smith"},
+	}
+	reviewer := &scriptedReviewer{}
+	d := &Deduplicator{Reviewer: reviewer}
+	if got := d.Deduplicate(contacts); len(got) != 2 {
+		t.Errorf("Deduplicate() returned %d contacts, want 2", len(got))
+	}
+	if d.ReviewErr != nil {
+		t.Errorf("Reviewer asked about a certain pair: %v", d.ReviewErr)
+	}
+}
+
+func TestTerminalReviewer(t *testing.T) {
+	c1 := &Contact{FirstName: "Louis", LastName: "Martin", Addresses: homeAddress(Address{ZipCode: "75011"})}
+	c2 := &Contact{FirstName: "Louis", LastName: "Martin", Addresses: homeAddress(Address{ZipCode: "69003"})}
+	match := Match{Score: 1.5, Evidence: []Evidence{{Signal: SignalFirstName, Weight: 1}}}
+
+	var out bytes.Buffer
+	tr := NewTerminalReviewer(strings.NewReader("what?\nn\n"), &out)
+	answer, err := tr.Review(c1, c2, match)
+	if err != nil || answer != AnswerNever {
+		t.Errorf("Review() = %v, %v, want %v", answer, err, AnswerNever)
+	}
+
+	printed := out.String()
+	for _, want := range []string{"Home address  75011      69003      *", "Last name     Martin     Martin", "Score 1.5: first_name +1", "[n]ever merge?"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Review() printed %q, want it to contain %q", printed, want)
+		}
+	}
+	if strings.Contains(printed, "Email") {
+		t.Errorf("Review() printed empty fields: %q", printed)
+	}
+
+	if _, err := tr.Review(c1, c2, match); !errors.Is(err, io.EOF) {
+		t.Errorf("Review() at end of input error = %v, want io.EOF", err)
+	}
+}
+
+func TestDeduplicatorReviewKeys(t *testing.T) {
+	// Every matching pair is uncertain
+	cfg := DefaultMatcherConfig()
+	cfg.Threshold, cfg.ReviewMax = 10, 10
+
+	tests := []struct {
+		name       string
+		contacts   []Contact
+		learned    []Override
+		unrecorded int
+	}{
+		{
+			name: "Shared email, told apart by ID",
+			contacts: []Contact{
+				{ID: "a1", FirstName: "Louis", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "famille@example.com"}},
+				{ID: "b2", FirstName: "Louis", LastName: "Martin", Emails: map[EmailType]string{EmailPersonal: "famille@example.com"}},
+			},
+			learned: []Override{{Action: OverrideNever, Key1: "a1", Key2: "b2"}},
+		},
+		{
+			name: "Known by phone only",
+			contacts: []Contact{
+				{FirstName: "Louis", LastName: "Martin", Phones: map[PhoneType]string{PhoneMobile1: "0611111111"}},
+				{FirstName: "Louis", LastName: "Martin"},
+			},
+			unrecorded: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Deduplicator{Matcher: NewWeightedMatcher(cfg), Reviewer: &scriptedReviewer{answers: []Answer{AnswerNever}}}
+			if got := d.Deduplicate(tt.contacts); len(got) != 2 {
+				t.Errorf("Deduplicate() returned %d contacts, want 2", len(got))
+			}
+			if !reflect.DeepEqual(d.Learned, tt.learned) || d.Unrecorded != tt.unrecorded {
+				t.Errorf("Learned, Unrecorded = %+v, %d, want %+v, %d", d.Learned, d.Unrecorded, tt.learned, tt.unrecorded)
+			}
+		})
+	}
+}
+
+func TestDeduplicatorReviewSkipsOverridden(t *testing.T) {
+	birthday := time.Date(1980, 4, 2, 0, 0, 0, 0, time.UTC)
+	otherBirthday := time.Date(2010, 6, 9, 0, 0, 0, 0, time.UTC)
+	contacts := []Contact{
+		{FirstName: "Louis", LastName: "Martin", Birthday: &birthday, Emails: map[EmailType]string{EmailPersonal: "louis@example.com"}},
+		{FirstName: "Louis", LastName: "Martin", Birthday: &otherBirthday, Emails: map[EmailType]string{EmailPersonal: "junior@example.com"}},
+	}
+	d := &Deduplicator{
+		Reviewer:  &scriptedReviewer{},
+		Overrides: []Override{{Action: OverrideNever, Key1: "junior@example.com", Key2: "louis@example.com"}},
+	}
+	if got := d.Deduplicate(contacts); len(got) != 2 {
+		t.Errorf("Deduplicate() returned %d contacts, want 2", len(got))
+	}
+	if d.ReviewErr != nil {
+		t.Errorf("Reviewer asked about a pair decided by an override: %v", d.ReviewErr)
+	}
+}
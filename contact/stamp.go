@@ -0,0 +1,89 @@
+package contact
+
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// Stamp records that a field had a value at a time. The value is kept as a
+// hash, enough to tell whether it changed since.
+type Stamp struct {
+	Time time.Time `json:"time"`
+	Hash string    `json:"hash"`
+}
+
+// ValueHash returns the hash of a field value, normalized so that formatting
+// changes are not changes (see sameValue).
+func ValueHash(f Field, value string) string {
+	h := fnv.New32a()
+	h.Write([]byte(normalizeValue(f, value)))
+	return strconv.FormatUint(uint64(h.Sum32()), 16)
+}
+
+// FieldTime returns when the value of a field last changed, as far as known:
+// the time of its stamp if the value did not change since, else the time of
+// its source, else UpdatedAt.
+func (c *Contact) FieldTime(f Field) (time.Time, bool) {
+	if s, ok := c.Stamps[f]; ok && s.Hash == ValueHash(f, c.FieldValue(f)) {
+		return s.Time, true
+	}
+	if t := c.Provenance[f].Time; !t.IsZero() {
+		return t, true
+	}
+	if c.UpdatedAt != nil {
+		return *c.UpdatedAt, true
+	}
+	return time.Time{}, false
+}
+
+// fieldTime is FieldTime, taking the stamp of the other contact when the
+// value is the one it stamped: an intranet value still equal to the value
+// last synchronized to Gmail did not change since.
+func fieldTime(c, other *Contact, f Field) (time.Time, bool) {
+	if _, ok := c.Stamps[f]; !ok {
+		if s, ok := other.Stamps[f]; ok && s.Hash == ValueHash(f, c.FieldValue(f)) {
+			return s.Time, true
+		}
+	}
+	return c.FieldTime(f)
+}
+
+// StampFields stamps the current value of every field: fields which did not
+// change since their stamp keep it, others are stamped with the time of their
+// source, or at when unknown.
+func (c *Contact) StampFields(at time.Time) {
+	for _, f := range Fields {
+		value := c.FieldValue(f)
+		if value == "" {
+			delete(c.Stamps, f)
+			continue
+		}
+
+		hash := ValueHash(f, value)
+		if s, ok := c.Stamps[f]; ok && s.Hash == hash {
+			continue
+		}
+		t := at
+		if pt := c.Provenance[f].Time; !pt.IsZero() {
+			t = pt
+		}
+		if c.Stamps == nil {
+			c.Stamps = make(map[Field]Stamp)
+		}
+		c.Stamps[f] = Stamp{Time: t, Hash: hash}
+	}
+}
+
+// takeStamp records that field f now holds the value of source.
+func (c *Contact) takeStamp(source *Contact, f Field) {
+	s, ok := source.Stamps[f]
+	if !ok {
+		delete(c.Stamps, f)
+		return
+	}
+	if c.Stamps == nil {
+		c.Stamps = make(map[Field]Stamp)
+	}
+	c.Stamps[f] = s
+}
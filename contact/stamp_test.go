@@ -0,0 +1,118 @@
+package contact
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValueHash(t *testing.T) {
+	if ValueHash(PhoneField(PhoneMobile1), "06 12 34 56 78") != ValueHash(PhoneField(PhoneMobile1), "+33612345678") {
+		t.Error("ValueHash() differs for two formats of a phone")
+	}
+	if ValueHash(AddressField(AddressHome), "Paris") != ValueHash(AddressField(AddressHome), " PARIS ") {
+		t.Error("ValueHash() differs for two cases of an address")
+	}
+	if ValueHash(AddressField(AddressHome), "Paris") == ValueHash(AddressField(AddressHome), "Lyon") {
+		t.Error("ValueHash() equal for two addresses")
+	}
+}
+
+func TestStampFields(t *testing.T) {
+	t1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	c := &Contact{
+		FirstName: "Marie",
+		Addresses: map[AddressType]Address{AddressHome: {City: "Lyon"}, AddressWork: {ZipCode: "69001"}},
+		Stamps: map[Field]Stamp{
+			FieldFirstName:             {Time: t1, Hash: ValueHash(FieldFirstName, "Marie")},
+			AddressField(AddressHome):  {Time: t1, Hash: ValueHash(AddressField(AddressHome), "Paris")},
+			AddressField(AddressOther): {Time: t1, Hash: ValueHash(AddressField(AddressOther), "France")},
+		},
+		Provenance: map[Field]Source{AddressField(AddressHome): {Name: "intranet", Time: t2}},
+	}
+	c.StampFields(now)
+
+	want := map[Field]Stamp{
+		FieldFirstName:            {Time: t1, Hash: ValueHash(FieldFirstName, "Marie")},             // Unchanged
+		AddressField(AddressHome): {Time: t2, Hash: ValueHash(AddressField(AddressHome), "Lyon")},   // Changed in the intranet
+		AddressField(AddressWork): {Time: now, Hash: ValueHash(AddressField(AddressWork), "69001")}, // Of unknown source
+	}
+	if len(c.Stamps) != len(want) {
+		t.Errorf("Stamps = %+v, want %+v", c.Stamps, want)
+	}
+	for f, s := range want {
+		if got := c.Stamps[f]; !got.Time.Equal(s.Time) || got.Hash != s.Hash {
+			t.Errorf("Stamps[%s] = %+v, want %+v", f, got, s)
+		}
+	}
+}
+
+func TestMergeContactStamps(t *testing.T) {
+	lastSync := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	intranetExport := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	gmailExport := time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// The parent moved, which the intranet knows, and the secretary fixed
+	// the mobile in Gmail. The intranet contact is stamped now as a whole.
+	intranet := Contact{
+		Addresses: homeAddress(Address{City: "Lyon"}),
+		Phones:    map[PhoneType]string{PhoneMobile1: "0611111111"},
+		UpdatedAt: &now,
+	}
+	intranet.SetSource(Source{Name: "intranet", Time: intranetExport})
+	gmail := Contact{
+		Addresses: homeAddress(Address{City: "Paris"}),
+		Phones:    map[PhoneType]string{PhoneMobile1: "0622222222"},
+		UpdatedAt: &lastSync,
+		Stamps: map[Field]Stamp{
+			AddressField(AddressHome): {Time: lastSync, Hash: ValueHash(AddressField(AddressHome), "Paris")},
+			PhoneField(PhoneMobile1):  {Time: lastSync, Hash: ValueHash(PhoneField(PhoneMobile1), "06 11 11 11 11")},
+		},
+	}
+	gmail.SetSource(Source{Name: "gmail", Time: gmailExport})
+
+	for _, tt := range []struct {
+		name                string
+		destination, source Contact
+	}{
+		{"Gmail merged into intranet", intranet, gmail},
+		{"Intranet merged into Gmail", gmail, intranet},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := MergeContacts(&tt.destination, &tt.source)
+			if merged.GetAddress(AddressHome).City != "Lyon" {
+				t.Errorf("City = %q, want the intranet change", merged.GetAddress(AddressHome).City)
+			}
+			if got := merged.GetPhone(PhoneMobile1); got != "0622222222" {
+				t.Errorf("Mobile = %q, want the Gmail change", got)
+			}
+
+			merged.StampFields(now)
+			if got := merged.Stamps[AddressField(AddressHome)].Time; !got.Equal(intranetExport) {
+				t.Errorf("City stamp = %v, want %v", got, intranetExport)
+			}
+			if got := merged.Stamps[PhoneField(PhoneMobile1)].Time; !got.Equal(gmailExport) {
+				t.Errorf("Mobile stamp = %v, want %v", got, gmailExport)
+			}
+		})
+	}
+}
+
+func TestMergeContactStampsUnchanged(t *testing.T) {
+	lastSync := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// Nothing changed: the intranet value gets the stamp of the last sync
+	intranet := Contact{Addresses: homeAddress(Address{City: "Lyon"}), UpdatedAt: &now}
+	intranet.SetSource(Source{Name: "intranet", Time: now})
+	gmail := Contact{Addresses: homeAddress(Address{City: "LYON"}), Stamps: map[Field]Stamp{AddressField(AddressHome): {Time: lastSync, Hash: ValueHash(AddressField(AddressHome), "Lyon")}}}
+
+	merged := MergeContacts(&intranet, &gmail)
+	merged.StampFields(now)
+	if got := merged.Stamps[AddressField(AddressHome)].Time; !got.Equal(lastSync) {
+		t.Errorf("City stamp = %v, want %v", got, lastSync)
+	}
+}
@@ -0,0 +1,87 @@
+package contact
+
+// baseIndex finds the previous version of a contact among the contacts of the
+// previous totem output, the common ancestor of the intranet and Gmail
+// versions in a three-way merge.
+type baseIndex struct {
+	previous []Contact
+	keys     map[string]int // IDs, member codes and normalized emails
+}
+
+func newBaseIndex(previous []Contact) *baseIndex {
+	index := &baseIndex{previous: previous, keys: make(map[string]int)}
+	add := func(key string, i int) {
+		if _, ok := index.keys[key]; key != "" && !ok {
+			index.keys[key] = i
+		}
+	}
+	for i := range previous {
+		add(previous[i].ID, i)
+	}
+	for i := range previous {
+		add(previous[i].MemberCode, i)
+	}
+	for i := range previous {
+		for _, email := range previous[i].Emails {
+			add(NormalizeEmail(email), i)
+		}
+	}
+	return index
+}
+
+// base returns the previous version of the contacts of a cluster, found by
+// ID first, then by member code, then by email, or nil if none.
+func (index *baseIndex) base(contacts []Contact, cluster []int) *Contact {
+	if len(index.previous) == 0 {
+		return nil
+	}
+	for _, j := range cluster {
+		if i, ok := index.keys[contacts[j].ID]; ok {
+			return &index.previous[i]
+		}
+	}
+	for _, j := range cluster {
+		if i, ok := index.keys[contacts[j].MemberCode]; ok {
+			return &index.previous[i]
+		}
+	}
+	for _, j := range cluster {
+		for _, et := range []EmailType{EmailPersonal, EmailDedicatedSGDF} {
+			if i, ok := index.keys[NormalizeEmail(contacts[j].GetEmail(et))]; ok {
+				return &index.previous[i]
+			}
+		}
+	}
+	return nil
+}
+
+// MergeContactWithBase is MergeContactWithPolicy as a three-way merge: base
+// is the previous version of the contact, from the last totem output. A
+// field changed since then on one side only takes the changed value, without
+// conflict. A field changed on both sides is a conflict, decided as by
+// MergeContactWithPolicy. Between values of different rules, the policy
+// still decides.
+// A value removed on one side is filled back from the other: an export does
+// not tell a removed value from a field it does not carry.
+func (c *Contact) MergeContactWithBase(source, base *Contact, policy MergePolicy) []Conflict {
+	return c.mergeContact(source, base, policy)
+}
+
+// resolveWithBase decides a field changed on one side only since the base,
+// replacing the destination value when the source one changed. It returns
+// false when both changed.
+func (m *merger) resolveWithBase(f Field, current, value string) (replace, ok bool) {
+	if m.base == nil {
+		return false, false
+	}
+	previous := m.base.FieldValue(f)
+	switch {
+	case previous == "":
+		return false, false
+	case sameValue(f, current, previous):
+		return true, true
+	case sameValue(f, value, previous):
+		return false, true
+	}
+	return false, false
+}
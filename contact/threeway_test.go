@@ -0,0 +1,120 @@
+package contact
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeContactWithBase(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	intranet := Source{Name: "intranet"}
+	gmail := Source{Name: "gmail"}
+
+	tests := []struct {
+		name        string
+		base        *Contact
+		destination Contact
+		source      Contact
+		policy      MergePolicy
+		wantCity    string
+		want        []Conflict
+	}{
+		{
+			name:        "Changed in the destination only",
+			base:        &Contact{Addresses: homeAddress(Address{City: "Paris"})},
+			destination: Contact{Addresses: homeAddress(Address{City: "Lyon"}), UpdatedAt: &older},
+			source:      Contact{Addresses: homeAddress(Address{City: "Paris"}), UpdatedAt: &newer},
+			wantCity:    "Lyon",
+		},
+		{
+			name:        "Changed in the source only",
+			base:        &Contact{Addresses: homeAddress(Address{City: "Paris"})},
+			destination: Contact{Addresses: homeAddress(Address{City: "PARIS"}), UpdatedAt: &newer},
+			source:      Contact{Addresses: homeAddress(Address{City: "Lyon"}), UpdatedAt: &older},
+			wantCity:    "Lyon",
+		},
+		{
+			name:        "Changed on both sides",
+			base:        &Contact{Addresses: homeAddress(Address{City: "Paris"})},
+			destination: Contact{Addresses: homeAddress(Address{City: "Lyon"}), UpdatedAt: &older},
+			source:      Contact{Addresses: homeAddress(Address{City: "Lille"}), UpdatedAt: &newer},
+			wantCity:    "Lille",
+			want:        []Conflict{{Field: AddressField(AddressHome), Kept: "Lille", Discarded: "Lyon", KeptSource: gmail, DiscardedSource: intranet, Base: "Paris"}},
+		},
+		{
+			name:        "Added on both sides",
+			base:        &Contact{},
+			destination: Contact{Addresses: homeAddress(Address{City: "Lyon"})},
+			source:      Contact{Addresses: homeAddress(Address{City: "Lille"})},
+			wantCity:    "Lyon",
+			want:        []Conflict{{Field: AddressField(AddressHome), Kept: "Lyon", Discarded: "Lille", KeptSource: intranet, DiscardedSource: gmail}},
+		},
+		{
+			name:        "Removed on one side",
+			base:        &Contact{Addresses: homeAddress(Address{City: "Paris"})},
+			destination: Contact{},
+			source:      Contact{Addresses: homeAddress(Address{City: "Paris"})},
+			wantCity:    "Paris",
+		},
+		{
+			name:        "Policy over the base",
+			base:        &Contact{Addresses: homeAddress(Address{City: "Paris"})},
+			destination: Contact{Addresses: homeAddress(Address{City: "Paris"})},
+			source:      Contact{Addresses: homeAddress(Address{City: "Lyon"})},
+			policy:      MergePolicy{"intranet": {AddressField(AddressHome): RuleAuthoritative}},
+			wantCity:    "Paris",
+			want:        []Conflict{{Field: AddressField(AddressHome), Kept: "Paris", Discarded: "Lyon", KeptSource: intranet, DiscardedSource: gmail, Base: "Paris"}},
+		},
+		{
+			name:        "Without base",
+			destination: Contact{Addresses: homeAddress(Address{City: "Paris"}), UpdatedAt: &older},
+			source:      Contact{Addresses: homeAddress(Address{City: "Lyon"}), UpdatedAt: &newer},
+			wantCity:    "Lyon",
+			want:        []Conflict{{Field: AddressField(AddressHome), Kept: "Lyon", Discarded: "Paris", KeptSource: gmail, DiscardedSource: intranet}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.destination.SetSource(intranet)
+			tt.source.SetSource(gmail)
+			got := tt.destination.MergeContactWithBase(&tt.source, tt.base, tt.policy)
+			if tt.destination.GetAddress(AddressHome).City != tt.wantCity {
+				t.Errorf("City = %q, want %q", tt.destination.GetAddress(AddressHome).City, tt.wantCity)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeContactWithBase() conflicts = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeduplicatorPrevious(t *testing.T) {
+	newer := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	contacts := []Contact{
+		// Intranet export, the parent moved
+		{FirstName: "Marie", LastName: "Dupont", Addresses: homeAddress(Address{City: "Lyon"}), Phones: map[PhoneType]string{PhoneMobile1: "0611111111"}, Emails: map[EmailType]string{EmailPersonal: "marie@example.com"}, UpdatedAt: &newer},
+		// Gmail export, the phone was fixed by hand
+		{FirstName: "Marie", LastName: "Dupont", Addresses: homeAddress(Address{City: "Paris"}), Phones: map[PhoneType]string{PhoneMobile1: "0622222222"}, Emails: map[EmailType]string{EmailPersonal: "Marie@Example.com"}},
+	}
+	d := &Deduplicator{Previous: []Contact{
+		{FirstName: "Paul", LastName: "Durand", Addresses: homeAddress(Address{City: "Paris"})},
+		{ID: "marie-id", FirstName: "Marie", LastName: "Dupont", Addresses: homeAddress(Address{City: "Paris"}), Phones: map[PhoneType]string{PhoneMobile1: "0611111111"}, Emails: map[EmailType]string{EmailPersonal: "marie@example.com"}},
+	}}
+
+	got := d.Deduplicate(contacts)
+	if len(got) != 1 {
+		t.Fatalf("Deduplicate() = %d contacts, want 1", len(got))
+	}
+	if got[0].GetAddress(AddressHome).City != "Lyon" || got[0].GetPhone(PhoneMobile1) != "0622222222" {
+		t.Errorf("Deduplicate() city, mobile = %q, %q, want Lyon, 0622222222", got[0].GetAddress(AddressHome).City, got[0].GetPhone(PhoneMobile1))
+	}
+	if got[0].ID != "marie-id" {
+		t.Errorf("Deduplicate() ID = %q, want the previous one", got[0].ID)
+	}
+	if len(d.Conflicts) != 0 {
+		t.Errorf("Conflicts = %+v, want none", d.Conflicts)
+	}
+}
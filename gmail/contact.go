@@ -5,9 +5,13 @@ import (
 	"log"
 	"strings"
 
+	"github.com/tinque/totem/address"
 	"github.com/tinque/totem/contact"
 )
 
+// idLabel is the label of the custom field holding the contact ID.
+const idLabel = "Identifiant Totem"
+
 type csvField struct {
 	Label string
 	Value string
@@ -44,6 +48,22 @@ var CSVHeader = []string{
 	"Address 1 - Region",
 	"Address 1 - Postal Code",
 	"Address 1 - PO Box",
+	"Address 2 - Label",
+	"Address 2 - Country",
+	"Address 2 - Street",
+	"Address 2 - Extended Address",
+	"Address 2 - City",
+	"Address 2 - Region",
+	"Address 2 - Postal Code",
+	"Address 2 - PO Box",
+	"Address 3 - Label",
+	"Address 3 - Country",
+	"Address 3 - Street",
+	"Address 3 - Extended Address",
+	"Address 3 - City",
+	"Address 3 - Region",
+	"Address 3 - Postal Code",
+	"Address 3 - PO Box",
 	"Organization Name",
 	"Organization Title",
 	"Organization Department",
@@ -54,12 +74,33 @@ var CSVHeader = []string{
 	"Custom Field 1 - Label",
 	"Custom Field 2 - Value",
 	"Custom Field 2 - Label",
-	// "Relation 1 - Label",
-	// "Relation 1 - Value",
-	// "Relation 2 - Label",
-	// "Relation 2 - Value",
-	// "Relation 3 - Label",
-	// "Relation 3 - Value",
+	"Custom Field 3 - Value",
+	"Custom Field 3 - Label",
+	"Custom Field 4 - Value",
+	"Custom Field 4 - Label",
+	"Relation 1 - Label",
+	"Relation 1 - Value",
+	"Relation 2 - Label",
+	"Relation 2 - Value",
+	"Relation 3 - Label",
+	"Relation 3 - Value",
+	"Relation 4 - Label",
+	"Relation 4 - Value",
+}
+
+// relationLabels are the Gmail labels of the relation types. Other labels,
+// set by hand in Gmail, are kept as is.
+var relationLabels = map[contact.RelationType]string{
+	contact.RelationChild:  "Child",
+	contact.RelationParent: "Parent",
+	contact.RelationSpouse: "Spouse",
+}
+
+// addressLabels are the Gmail labels of the address types.
+var addressLabels = map[contact.AddressType]string{
+	contact.AddressHome:  "Domicile",
+	contact.AddressWork:  "Travail",
+	contact.AddressOther: "Autre",
 }
 
 func getHeaderIndex(header string) int {
@@ -122,10 +163,55 @@ func mapPhonesToCSV(row []string, c contact.Contact) {
 	}
 }
 
+func mapRelationsToCSV(row []string, c contact.Contact) {
+	for i, r := range c.Relations {
+		if i >= 4 { // Limite à 4 relations maximum
+			break
+		}
+
+		label, ok := relationLabels[r.Type]
+		if !ok {
+			label = string(r.Type)
+		}
+		row[getHeaderIndex(fmt.Sprintf("Relation %d - Label", i+1))] = label
+		row[getHeaderIndex(fmt.Sprintf("Relation %d - Value", i+1))] = r.Name
+	}
+}
+
+// mapAddressesToCSV writes the addresses in the order of contact.AddressTypes,
+// the parsed street line in Street and its complement in Extended Address.
+// Streets without a house number nor a street type, such as "BP 12" or a
+// locality, are kept as typed.
+func mapAddressesToCSV(row []string, c contact.Contact) {
+	i := 1
+	for _, at := range contact.AddressTypes {
+		a := c.GetAddress(at)
+		if a.IsZero() {
+			continue
+		}
+		row[getHeaderIndex(fmt.Sprintf("Address %d - Label", i))] = addressLabels[at]
+		if street := address.Parse(a.Street); street.Number != "" || street.Type != "" {
+			row[getHeaderIndex(fmt.Sprintf("Address %d - Street", i))] = street.Line()
+			row[getHeaderIndex(fmt.Sprintf("Address %d - Extended Address", i))] = street.Complement
+		} else {
+			row[getHeaderIndex(fmt.Sprintf("Address %d - Street", i))] = a.Street
+		}
+		row[getHeaderIndex(fmt.Sprintf("Address %d - Postal Code", i))] = a.ZipCode
+		row[getHeaderIndex(fmt.Sprintf("Address %d - City", i))] = a.City
+		row[getHeaderIndex(fmt.Sprintf("Address %d - Country", i))] = a.Country
+		i++
+	}
+}
+
 func CSVContact(c contact.Contact) []string {
 	row := make([]string, len(CSVHeader))
 
 	// Custom fields
+	if c.ID != "" {
+		row[getHeaderIndex("Custom Field 4 - Label")] = idLabel
+		row[getHeaderIndex("Custom Field 4 - Value")] = c.ID
+	}
+
 	if c.MemberCode != "" {
 		row[getHeaderIndex("Custom Field 1 - Label")] = "Code Adhérent"
 		row[getHeaderIndex("Custom Field 1 - Value")] = c.MemberCode
@@ -136,6 +222,11 @@ func CSVContact(c contact.Contact) []string {
 		row[getHeaderIndex("Custom Field 2 - Value")] = c.UpdatedAt.Format("2006-01-02 15:04:05")
 	}
 
+	if len(c.Stamps) > 0 {
+		row[getHeaderIndex("Custom Field 3 - Label")] = stampsLabel
+		row[getHeaderIndex("Custom Field 3 - Value")] = encodeStamps(c.Stamps)
+	}
+
 	// Base information
 	row[getHeaderIndex("First Name")] = c.FirstName
 	row[getHeaderIndex("Last Name")] = c.LastName
@@ -149,12 +240,28 @@ func CSVContact(c contact.Contact) []string {
 	mapEmailsToCSV(row, c)
 	mapPhonesToCSV(row, c)
 
-	// Address information
-	row[getHeaderIndex("Address 1 - Label")] = "Domicile" // Address 1 - Label
-	row[getHeaderIndex("Address 1 - Street")] = c.Address
-	row[getHeaderIndex("Address 1 - Postal Code")] = c.ZipCode
-	row[getHeaderIndex("Address 1 - City")] = c.City
-	row[getHeaderIndex("Address 1 - Country")] = c.Country
+	// Relations
+	mapRelationsToCSV(row, c)
+
+	// Addresses
+	mapAddressesToCSV(row, c)
 
 	return row
 }
+
+// ProvenanceNotes describes the source of each field of the contact, one
+// field per line, for the Notes field.
+func ProvenanceNotes(c contact.Contact) string {
+	var lines []string
+	for _, f := range contact.Fields {
+		if src, ok := c.Provenance[f]; ok {
+			lines = append(lines, fmt.Sprintf("%s: %s", f, src))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetNotes sets the Notes field of a row made by CSVContact.
+func SetNotes(row []string, notes string) {
+	row[getHeaderIndex("Notes")] = notes
+}
@@ -0,0 +1,99 @@
+package gmail
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tinque/totem/contact"
+	"github.com/tinque/totem/parser"
+)
+
+// csvRow reads a CSV record as ExtractGmailContact gets it, by header.
+func csvRow(record []string) parser.Row {
+	row := make(parser.Row, len(CSVHeader))
+	for i, h := range CSVHeader {
+		row[h] = record[i]
+	}
+	return row
+}
+
+func TestRelationsRoundTrip(t *testing.T) {
+	c := contact.Contact{
+		FirstName: "Marie",
+		LastName:  "Martin",
+		Relations: []contact.Relation{
+			{Type: contact.RelationChild, Name: "Louis Martin", Key: "111"},
+			{Type: contact.RelationChild, Name: "Léa Martin", Key: "222"},
+			{Type: contact.RelationSpouse, Name: "Pierre Martin"},
+			{Type: "Grand-mère", Name: "Jeanne Durand"},
+			{Type: contact.RelationChild, Name: "Paul Martin"}, // Over the 4 columns
+		},
+	}
+
+	record := CSVContact(c)
+	if got := record[getHeaderIndex("Relation 3 - Label")]; got != "Spouse" {
+		t.Errorf("Relation 3 - Label = %q, want Spouse", got)
+	}
+
+	got, err := ExtractGmailContact(csvRow(record))
+	if err != nil {
+		t.Fatalf("ExtractGmailContact() error = %v", err)
+	}
+	// Keys are not exported, the fifth relation is dropped
+	want := []contact.Relation{
+		{Type: contact.RelationChild, Name: "Louis Martin"},
+		{Type: contact.RelationChild, Name: "Léa Martin"},
+		{Type: contact.RelationSpouse, Name: "Pierre Martin"},
+		{Type: "Grand-mère", Name: "Jeanne Durand"},
+	}
+	if !reflect.DeepEqual(got.Relations, want) {
+		t.Errorf("Relations = %+v, want %+v", got.Relations, want)
+	}
+}
+
+func TestExtractRelationLabel(t *testing.T) {
+	// A label typed by hand in Gmail, in any case, is a known type
+	record := CSVContact(contact.Contact{FirstName: "Marie"})
+	record[getHeaderIndex("Relation 1 - Label")] = "spouse"
+	record[getHeaderIndex("Relation 1 - Value")] = "Pierre Martin"
+
+	got, err := ExtractGmailContact(csvRow(record))
+	if err != nil {
+		t.Fatalf("ExtractGmailContact() error = %v", err)
+	}
+	want := []contact.Relation{{Type: contact.RelationSpouse, Name: "Pierre Martin"}}
+	if !reflect.DeepEqual(got.Relations, want) {
+		t.Errorf("Relations = %+v, want %+v", got.Relations, want)
+	}
+	if got := CSVContact(got)[getHeaderIndex("Relation 1 - Label")]; got != "Spouse" {
+		t.Errorf("Relation 1 - Label written back = %q, want Spouse", got)
+	}
+}
+
+func TestAddressesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		street string
+		want   string // Street read back
+	}{
+		{"street line", "3 RUE DES LILAS BAT B", "3 rue des Lilas\nBâtiment B"},
+		{"post office box", "BP 12", "BP 12"},
+		{"locality", "LE HAUT DU BOIS\nCS 30012", "LE HAUT DU BOIS\nCS 30012"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := contact.Address{Street: tt.street, ZipCode: "75011", City: "Paris"}
+			c := contact.Contact{FirstName: "Marie", Addresses: map[contact.AddressType]contact.Address{contact.AddressHome: a}}
+
+			got, err := ExtractGmailContact(csvRow(CSVContact(c)))
+			if err != nil {
+				t.Fatalf("ExtractGmailContact() error = %v", err)
+			}
+			want := contact.Address{Street: tt.want, ZipCode: "75011", City: "Paris"}
+			if home := got.GetAddress(contact.AddressHome); home != want {
+				t.Errorf("home address = %+v, want %+v", home, want)
+			}
+		})
+	}
+}
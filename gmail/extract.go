@@ -19,6 +19,14 @@ func extractCSVCustomField(label, value string, c *contact.Contact) {
 			c.UpdatedAt = &t
 		}
 	}
+
+	if label == idLabel && value != "" {
+		c.ID = value
+	}
+
+	if label == stampsLabel && value != "" {
+		c.Stamps = decodeStamps(value)
+	}
 }
 
 func extractCSVEmail(label, value string, c *contact.Contact) {
@@ -55,11 +63,47 @@ func extractCSVPhone(label, value string, c *contact.Contact) {
 	}
 }
 
+func extractCSVRelation(label, value string, c *contact.Contact) {
+	if value == "" {
+		return
+	}
+	t := contact.RelationType(label)
+	for rt, l := range relationLabels {
+		if strings.EqualFold(label, l) {
+			t = rt
+		}
+	}
+	c.Relations = append(c.Relations, contact.Relation{Type: t, Name: value})
+}
+
+// extractCSVAddress reads an address, typed by its label. Unlabeled
+// addresses are home addresses and unknown labels other addresses.
+func extractCSVAddress(label string, a contact.Address, c *contact.Contact) {
+	if a.IsZero() {
+		return
+	}
+	at := contact.AddressOther
+	switch {
+	case label == "", strings.EqualFold(label, "Home"):
+		at = contact.AddressHome
+	case strings.EqualFold(label, "Work"):
+		at = contact.AddressWork
+	}
+	for t, l := range addressLabels {
+		if strings.EqualFold(label, l) {
+			at = t
+		}
+	}
+	if c.GetAddress(at).IsZero() {
+		c.SetAddress(at, a)
+	}
+}
+
 func ExtractGmailContact(row parser.Row) (contact.Contact, error) {
 	c := contact.Contact{}
 
 	// Custom fields
-	for i := 1; i <= 2; i++ {
+	for i := 1; i <= 4; i++ {
 		if v, ok := row[fmt.Sprintf("Custom Field %d - Value", i)]; ok {
 			if l, ok := row[fmt.Sprintf("Custom Field %d - Label", i)]; ok {
 				extractCSVCustomField(l, v, &c)
@@ -114,18 +158,29 @@ func ExtractGmailContact(row parser.Row) (contact.Contact, error) {
 		}
 	}
 
-	// Address information
-	if v, ok := row["Address 1 - Street"]; ok {
-		c.Address = v
-	}
-	if v, ok := row["Address 1 - Postal Code"]; ok {
-		c.ZipCode = v
-	}
-	if v, ok := row["Address 1 - City"]; ok {
-		c.City = v
+	// Relations
+	for i := 1; i <= 4; i++ {
+		if v, ok := row[fmt.Sprintf("Relation %d - Value", i)]; ok {
+			if l, ok := row[fmt.Sprintf("Relation %d - Label", i)]; ok {
+				extractCSVRelation(l, v, &c)
+			}
+		}
 	}
-	if v, ok := row["Address 1 - Country"]; ok {
-		c.Country = v
+
+	// Addresses
+	for i := 1; i <= 3; i++ {
+		// The complement is in the extended address (see mapAddressesToCSV)
+		street := row[fmt.Sprintf("Address %d - Street", i)]
+		if v := row[fmt.Sprintf("Address %d - Extended Address", i)]; v != "" {
+			street = strings.TrimSpace(street + "\n" + v)
+		}
+		a := contact.Address{
+			Street:  street,
+			ZipCode: row[fmt.Sprintf("Address %d - Postal Code", i)],
+			City:    row[fmt.Sprintf("Address %d - City", i)],
+			Country: row[fmt.Sprintf("Address %d - Country", i)],
+		}
+		extractCSVAddress(row[fmt.Sprintf("Address %d - Label", i)], a, &c)
 	}
 
 	return c, nil
@@ -0,0 +1,48 @@
+package gmail
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tinque/totem/contact"
+)
+
+// stampsLabel is the label of the custom field holding the field stamps.
+const stampsLabel = "Horodatage des champs"
+
+// encodeStamps writes the stamps as "field=time/hash" entries separated by
+// semicolons, in the order of contact.Fields.
+func encodeStamps(stamps map[contact.Field]contact.Stamp) string {
+	var entries []string
+	for _, f := range contact.Fields {
+		if s, ok := stamps[f]; ok {
+			entries = append(entries, string(f)+"="+s.Time.UTC().Format(time.RFC3339)+"/"+s.Hash)
+		}
+	}
+	return strings.Join(entries, ";")
+}
+
+// decodeStamps reads stamps written by encodeStamps, skipping malformed
+// entries.
+func decodeStamps(value string) map[contact.Field]contact.Stamp {
+	var stamps map[contact.Field]contact.Stamp
+	for entry := range strings.SplitSeq(value, ";") {
+		f, stamp, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		ts, hash, ok := strings.Cut(stamp, "/")
+		if !ok || hash == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			continue
+		}
+		if stamps == nil {
+			stamps = make(map[contact.Field]contact.Stamp)
+		}
+		stamps[contact.Field(f)] = contact.Stamp{Time: t, Hash: hash}
+	}
+	return stamps
+}
@@ -2,25 +2,56 @@ package main
 
 import (
 	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/tinque/totem/address"
 	"github.com/tinque/totem/contact"
 	"github.com/tinque/totem/gmail"
 	"github.com/tinque/totem/parser"
 	"github.com/tinque/totem/sgdf"
+	"github.com/tinque/totem/state"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiff(os.Args[2:])
+		return
+	}
+
 	intranetPath := flag.String("intranet", "", "Path to intranet extract file (required)")
 	gmailPath := flag.String("gmail", "", "Path to Gmail contacts CSV file (optional)")
 	outputPath := flag.String("out", "output.csv", "Path to output CSV file (optional)")
+	encoding := flag.String("encoding", "", "Character encoding of the intranet export, e.g. windows-1252 or iso-8859-1 (optional, detected by default)")
+	matchConfigPath := flag.String("match-config", "", "Path to a JSON file with duplicate detection weights and threshold (optional)")
+	newPath := flag.String("new", "", "Path to a CSV file of the output contacts not in the Gmail export, to import (optional, requires -gmail)")
+	changedPath := flag.String("changed", "", "Path to a CSV file of the output contacts changed from the Gmail export, to import and merge (optional, requires -gmail)")
+	previousPath := flag.String("previous", "", "Path to the previous output CSV file, for a three-way merge (optional)")
+	statePath := flag.String("state", "", "Path to the local sync state file, read and updated on each run (optional)")
+	mergePolicyPath := flag.String("merge-policy", "", "Path to a JSON file of per-source, per-field merge rules (optional)")
+	overridesPath := flag.String("overrides", "", "Path to a CSV file of manual always/never merge decisions (optional)")
+	decisionsPath := flag.String("decisions", "decisions.csv", "Path to the CSV file recording the always/never answers of the review, applied on every run")
+	nonInteractive := flag.Bool("non-interactive", false, "Decide uncertain duplicates automatically instead of asking (for scripted runs)")
+	provenancePath := flag.String("provenance", "", "Path to a CSV report of the source of each contact field (optional)")
+	provenanceNotes := flag.Bool("provenance-notes", false, "Write the source of each contact field in the Gmail Notes field")
+	conflictsPath := flag.String("conflicts", "", "Path to a CSV report of the fields for which merged contacts disagreed (optional)")
+	explainPath := flag.String("explain", "", "Path to a report explaining the deduplication decisions (optional)")
+	explainFormat := flag.String("explain-format", "text", "Format of the explain report: text or json")
+	householdsPath := flag.String("households", "", "Path to an export of the contacts grouped by household (optional)")
+	householdsFormat := flag.String("households-format", "csv", "Format of the households export: csv or json")
+	postalCodesPath := flag.String("postal-codes", "", "Path to a CSV dataset of postal codes and communes, e.g. from La Poste, to validate the addresses (optional)")
+	addressReportPath := flag.String("address-report", "", "Path to a CSV report of the invalid addresses, with suggested corrections (optional, requires -postal-codes)")
 
 	flag.Usage = func() {
-		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -intranet <extract-intranet> [-gmail <contacts.csv>] [-out <output.csv>]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -intranet <extract-intranet> [-gmail <contacts.csv>] [-out <output.csv>] [-new <new.csv>] [-changed <changed.csv>] [-previous <output.csv>] [-state <state.json>] [-encoding <charset>] [-match-config <config.json>] [-merge-policy <policy.json>] [-overrides <overrides.csv>] [-decisions <decisions.csv>] [-non-interactive] [-provenance <report.csv>] [-provenance-notes] [-conflicts <conflicts.csv>] [-explain <report> [-explain-format text|json]] [-households <export> [-households-format csv|json]] [-postal-codes <codes.csv> [-address-report <report.csv>]]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s diff [-encoding <charset>] [-match-config <config.json>] <old> <new>\n", os.Args[0])
 		flag.PrintDefaults()
 	}
 	flag.Parse()
@@ -31,29 +62,149 @@ func main() {
 		os.Exit(2)
 	}
 
-	cIList := contactFromIntranet(*intranetPath)
+	if *householdsFormat != "csv" && *householdsFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown -households-format %q, want csv or json.\n", *householdsFormat)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if (*newPath != "" || *changedPath != "") && *gmailPath == "" {
+		fmt.Fprintln(os.Stderr, "The -new and -changed parameters require -gmail.")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *addressReportPath != "" && *postalCodesPath == "" {
+		fmt.Fprintln(os.Stderr, "The -address-report parameter requires -postal-codes.")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *explainFormat != "text" && *explainFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown -explain-format %q, want text or json.\n", *explainFormat)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dedup := newDeduplicator(*matchConfigPath, *overridesPath, *decisionsPath)
+	if *mergePolicyPath != "" {
+		dedup.Policy = loadMergePolicy(*mergePolicyPath)
+	}
+	var st *state.State
+	if *statePath != "" {
+		st = loadState(*statePath)
+	}
+	if *previousPath != "" {
+		dedup.Previous = readGmail(*previousPath)
+	} else if st != nil {
+		dedup.Previous = st.Previous()
+	}
+	dedup.Explain = *explainPath != ""
+	if !*nonInteractive {
+		dedup.Reviewer = contact.NewTerminalReviewer(os.Stdin, os.Stderr)
+	}
+
+	cIList := contactFromIntranet(*intranetPath, *encoding, dedup)
 	cList := cIList
 	if *gmailPath != "" {
-		cGList := contactFromGmail(*gmailPath)
+		cGList := contactFromGmail(*gmailPath, dedup)
 		cList = append(cList, cGList...)
 	}
-	cList = contact.DeduplicateAndMergeContacts(cList)
+	cList = dedup.Deduplicate(cList)
+	contact.ResolveRelations(cList)
 
-	// Set the updated at timestamp
+	if *postalCodesPath != "" {
+		codes, src := loadPostalCodes(*postalCodesPath)
+		issues := contact.CheckAddresses(cList, codes, src)
+		fmt.Fprintf(os.Stderr, "%d invalid addresses\n", len(issues))
+		if *addressReportPath != "" {
+			writeAddressReport(*addressReportPath, issues)
+		}
+	}
+
+	if errors.Is(dedup.ReviewErr, io.EOF) {
+		fmt.Fprintln(os.Stderr, "No more answers, the remaining uncertain duplicates were decided automatically.")
+	} else if dedup.ReviewErr != nil {
+		log.Printf("error reviewing duplicates, the remaining ones were decided automatically: %v", dedup.ReviewErr)
+	}
+	if len(dedup.Learned) > 0 {
+		appendDecisions(*decisionsPath, dedup.Learned)
+	}
+	if dedup.Unrecorded > 0 {
+		fmt.Fprintf(os.Stderr, "%d always or never answers could not be recorded, the contacts having no member code, email or ID to tell them apart: they apply to this run only.\n", dedup.Unrecorded)
+	}
+
+	if *provenancePath != "" {
+		writeProvenance(*provenancePath, cList)
+	}
+
+	if *conflictsPath != "" {
+		writeConflicts(*conflictsPath, dedup.Conflicts)
+	}
+
+	if *explainPath != "" {
+		writeExplain(*explainPath, *explainFormat, dedup.Decisions)
+	}
+
+	if *householdsPath != "" {
+		writeHouseholds(*householdsPath, *householdsFormat, contact.BuildHouseholds(cList))
+	}
+
+	// Identify and stamp the fields, so that the next run recognizes the
+	// contacts and knows which side changed their fields
 	now := time.Now()
 	for i := range cList {
-		cList[i].UpdatedAt = &now
+		cList[i].AssignID()
+		cList[i].StampFields(now)
+	}
+
+	writeGmailCSV(*outputPath, cList, *provenanceNotes)
+
+	if *newPath != "" || *changedPath != "" {
+		// Compare with the contacts as they are in Gmail
+		existing := readGmail(*gmailPath)
+		for i := range existing {
+			existing[i].RemoveLabel(contact.Label("* myContacts"))
+		}
+		diff := contact.DiffContacts(existing, cList, dedup.Matcher)
+		if *newPath != "" {
+			writeGmailCSV(*newPath, diff.Added, *provenanceNotes)
+		}
+		if *changedPath != "" {
+			changed := make([]contact.Contact, 0, len(diff.Modified))
+			for _, change := range diff.Modified {
+				changed = append(changed, change.Import())
+			}
+			writeGmailCSV(*changedPath, changed, *provenanceNotes)
+		}
+	}
+
+	if st != nil {
+		added, changed := st.Changes(cList)
+		fmt.Fprintf(os.Stderr, "%d new and %d changed contacts since the last run\n", len(added), len(changed))
+		st.Record(cList, now)
+		if err := st.Save(*statePath); err != nil {
+			log.Fatalf("error saving state %q: %v", *statePath, err)
+		}
 	}
 
+}
+
+// writeGmailCSV writes contacts as a Gmail CSV file.
+func writeGmailCSV(path string, contacts []contact.Contact, provenanceNotes bool) {
 	csvContent := [][]string{}
 	csvContent = append(csvContent, gmail.CSVHeader)
-	for _, c := range cList {
-		csvContent = append(csvContent, gmail.CSVContact(c))
+	for _, c := range contacts {
+		row := gmail.CSVContact(c)
+		if provenanceNotes {
+			gmail.SetNotes(row, gmail.ProvenanceNotes(c))
+		}
+		csvContent = append(csvContent, row)
 	}
 
-	of, err := os.Create(*outputPath)
+	of, err := os.Create(path)
 	if err != nil {
-		log.Fatalf("error creating output file %q: %v", *outputPath, err)
+		log.Fatalf("error creating output file %q: %v", path, err)
 	}
 	defer of.Close()
 
@@ -62,11 +213,226 @@ func main() {
 		log.Fatalln("error writing csv:", err)
 	}
 
-	fmt.Fprintln(os.Stderr, "wrote", *outputPath)
+	fmt.Fprintln(os.Stderr, "wrote", path)
+}
+
+func loadMatcher(matchConfigPath string) *contact.WeightedMatcher {
+	cfg := contact.DefaultMatcherConfig()
+	if matchConfigPath != "" {
+		f, err := os.Open(matchConfigPath)
+		if err != nil {
+			log.Fatalf("error opening match config %q: %v", matchConfigPath, err)
+		}
+		defer f.Close()
+
+		cfg, err = contact.LoadMatcherConfig(f)
+		if err != nil {
+			log.Fatalf("error loading match config %q: %v", matchConfigPath, err)
+		}
+	}
+	return contact.NewWeightedMatcher(cfg)
+}
+
+// runDiff compares two exports, Gmail CSV files or intranet exports, and
+// prints the contacts added, removed and modified.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	encoding := fs.String("encoding", "", "Character encoding of the intranet export, e.g. windows-1252 or iso-8859-1 (optional, detected by default)")
+	matchConfigPath := fs.String("match-config", "", "Path to a JSON file with duplicate detection weights and threshold (optional)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s diff [-encoding <charset>] [-match-config <config.json>] <old> <new>\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Files ending in .csv are read as Gmail exports, others as intranet exports.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	matcher := loadMatcher(*matchConfigPath)
+	dedup := &contact.Deduplicator{Matcher: matcher}
+	readExport := func(path string) []contact.Contact {
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			return dedup.Deduplicate(readGmail(path))
+		}
+		return contactFromIntranet(path, *encoding, dedup)
+	}
+	old, new := readExport(fs.Arg(0)), readExport(fs.Arg(1))
+
+	diff := contact.DiffContacts(old, new, matcher)
+	if err := contact.WriteDiffText(os.Stdout, diff); err != nil {
+		log.Fatalln("error writing diff:", err)
+	}
+}
+
+func newDeduplicator(matchConfigPath, overridesPath, decisionsPath string) *contact.Deduplicator {
+	dedup := &contact.Deduplicator{Matcher: loadMatcher(matchConfigPath)}
+
+	if overridesPath != "" {
+		dedup.Overrides = loadOverrides(overridesPath)
+	}
+	// The decisions of the previous reviews, if any
+	if _, err := os.Stat(decisionsPath); err == nil {
+		dedup.Overrides = append(dedup.Overrides, loadOverrides(decisionsPath)...)
+	}
 
+	return dedup
 }
 
-func contactFromIntranet(path string) []contact.Contact {
+func loadMergePolicy(path string) contact.MergePolicy {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("error opening merge policy %q: %v", path, err)
+	}
+	defer f.Close()
+
+	policy, err := contact.LoadMergePolicy(f)
+	if err != nil {
+		log.Fatalf("error loading merge policy %q: %v", path, err)
+	}
+	return policy
+}
+
+// loadPostalCodes reads a postal code dataset, and returns it with its source
+// for the provenance of the addresses it normalizes.
+func loadPostalCodes(path string) (*address.PostalCodes, contact.Source) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("error opening postal codes %q: %v", path, err)
+	}
+	defer f.Close()
+
+	codes, err := address.LoadPostalCodes(f)
+	if err != nil {
+		log.Fatalf("error loading postal codes %q: %v", path, err)
+	}
+	return codes, contact.Source{Name: "postal-codes", File: path}
+}
+
+func loadOverrides(path string) []contact.Override {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("error opening overrides %q: %v", path, err)
+	}
+	defer f.Close()
+
+	overrides, err := contact.LoadOverrides(f)
+	if err != nil {
+		log.Fatalf("error loading overrides %q: %v", path, err)
+	}
+	return overrides
+}
+
+func appendDecisions(path string, decisions []contact.Override) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		log.Fatalf("error opening decisions file %q: %v", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Fatalf("error opening decisions file %q: %v", path, err)
+	}
+	if err := contact.WriteOverrides(f, decisions, info.Size() == 0); err != nil {
+		log.Fatalf("error writing decisions file %q: %v", path, err)
+	}
+
+	fmt.Fprintln(os.Stderr, "wrote", path)
+}
+
+func writeExplain(path, format string, decisions []contact.PairDecision) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("error creating explain report %q: %v", path, err)
+	}
+	defer f.Close()
+
+	if format == "json" {
+		err = contact.WriteExplainJSON(f, decisions)
+	} else {
+		err = contact.WriteExplainText(f, decisions)
+	}
+	if err != nil {
+		log.Fatalf("error writing explain report %q: %v", path, err)
+	}
+
+	fmt.Fprintln(os.Stderr, "wrote", path)
+}
+
+func writeHouseholds(path, format string, households []contact.Household) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("error creating households export %q: %v", path, err)
+	}
+	defer f.Close()
+
+	if format == "json" {
+		err = contact.WriteHouseholdsJSON(f, households)
+	} else {
+		err = contact.WriteHouseholdsCSV(f, households)
+	}
+	if err != nil {
+		log.Fatalf("error writing households export %q: %v", path, err)
+	}
+
+	fmt.Fprintln(os.Stderr, "wrote", path)
+}
+
+func writeProvenance(path string, contacts []contact.Contact) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("error creating provenance report %q: %v", path, err)
+	}
+	defer f.Close()
+
+	if err := contact.WriteProvenanceCSV(f, contacts); err != nil {
+		log.Fatalf("error writing provenance report %q: %v", path, err)
+	}
+
+	fmt.Fprintln(os.Stderr, "wrote", path)
+}
+
+func writeAddressReport(path string, issues []contact.AddressIssue) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("error creating address report %q: %v", path, err)
+	}
+	defer f.Close()
+
+	if err := contact.WriteAddressIssuesCSV(f, issues); err != nil {
+		log.Fatalf("error writing address report %q: %v", path, err)
+	}
+
+	fmt.Fprintln(os.Stderr, "wrote", path)
+}
+
+func writeConflicts(path string, conflicts []contact.Conflict) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("error creating conflicts report %q: %v", path, err)
+	}
+	defer f.Close()
+
+	if err := contact.WriteConflictsCSV(f, conflicts); err != nil {
+		log.Fatalf("error writing conflicts report %q: %v", path, err)
+	}
+
+	fmt.Fprintln(os.Stderr, "wrote", path)
+}
+
+// modTime returns the modification time of a source file, taken as the time
+// its data was produced.
+func modTime(f *os.File) time.Time {
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func contactFromIntranet(path, encoding string, dedup *contact.Deduplicator) []contact.Contact {
 	f, err := os.Open(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
@@ -74,47 +440,91 @@ func contactFromIntranet(path string) []contact.Contact {
 	}
 	defer f.Close()
 
-	rows, err := parser.FromExcelHTMLReader(f)
+	rows, err := parser.FromExcelReader(f, encoding)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing file: %v\n", err)
 		os.Exit(2)
 	}
 
+	src := contact.Source{Name: "intranet", File: path, Time: modTime(f)}
 	cList := []contact.Contact{}
 	for row := range rows {
+		src.Row++
 		c, err := sgdf.ExtractIntranetContact(row)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error extracting contact: %v\n", err)
 			continue
 		}
 
+		for i := range c {
+			c[i].SetSource(src)
+		}
 		cList = append(cList, c...)
 	}
 
-	cList = contact.DeduplicateAndMergeContacts(cList)
+	cList = dedup.Deduplicate(cList)
 
 	return cList
 }
 
-func contactFromGmail(path string) []contact.Contact {
+func loadState(path string) *state.State {
+	st, err := state.Load(path)
+	if err != nil {
+		log.Fatalf("error loading state %q: %v", path, err)
+	}
+	return st
+}
+
+// readGmail reads the contacts of a Gmail CSV file as is, neither cleaned
+// nor deduplicated: a previous output, the common ancestors of a three-way
+// merge, or an export to diff. Gmail exports are UTF-8, so the encoding is
+// always detected.
+func readGmail(path string) []contact.Contact {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("error opening %q: %v", path, err)
+	}
+	defer f.Close()
+
+	rows, err := parser.FromCSVReader(f, "")
+	if err != nil {
+		log.Fatalf("error parsing %q: %v", path, err)
+	}
+
+	cList := []contact.Contact{}
+	for row := range rows {
+		c, err := gmail.ExtractGmailContact(row)
+		if err != nil {
+			log.Printf("Error extracting contact: %v", err)
+			continue
+		}
+		cList = append(cList, c)
+	}
+	return cList
+}
+
+func contactFromGmail(path string, dedup *contact.Deduplicator) []contact.Contact {
 	f, err := os.Open(path)
 	if err != nil {
 		log.Fatalf("Error opening contacts.csv: %v", err)
 	}
 	defer f.Close()
 
-	rows, err := parser.FromCSVReader(f)
+	rows, err := parser.FromCSVReader(f, "")
 	if err != nil {
 		log.Fatalf("Error parsing contacts.csv: %v", err)
 	}
 
+	src := contact.Source{Name: "gmail", File: path, Time: modTime(f)}
 	cList := []contact.Contact{}
 	for row := range rows {
+		src.Row++
 		c, err := gmail.ExtractGmailContact(row)
 		if err != nil {
 			log.Printf("Error extracting contact: %v", err)
 			continue
 		}
+		c.SetSource(src)
 
 		// clear labels
 		c.ClearManagedLabels()
@@ -124,7 +534,7 @@ func contactFromGmail(path string) []contact.Contact {
 		cList = append(cList, c)
 	}
 
-	cList = contact.DeduplicateAndMergeContacts(cList)
+	cList = dedup.Deduplicate(cList)
 
 	return cList
 }
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
 	"io"
@@ -8,8 +9,19 @@ import (
 )
 
 // FromCSVReader parses un export CSV et retourne les lignes.
-func FromCSVReader(r io.Reader) (iter.Seq[Row], error) {
-	reader := csv.NewReader(r)
+// The content is transcoded to UTF-8 from the given encoding, or from the
+// detected one when encoding is empty.
+func FromCSVReader(r io.Reader, encoding string) (iter.Seq[Row], error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %v", err)
+	}
+	b, err = toUTF8(b, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding CSV: %v", err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(b))
 
 	headers, err := reader.Read()
 	if err != nil {
@@ -25,15 +37,7 @@ func FromCSVReader(r io.Reader) (iter.Seq[Row], error) {
 			if err != nil {
 				return
 			}
-			row := make(Row)
-			for i, value := range record {
-				if i < len(headers) {
-					row[headers[i]] = value
-				} else {
-					row[fmt.Sprintf("extra_%d", i-len(headers)+1)] = value
-				}
-			}
-			if !yield(row) {
+			if !yield(newRow(headers, record)) {
 				return
 			}
 		}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/unicode/norm"
+)
+
+// fallbackEncoding is used when the input is not valid UTF-8: Windows-1252
+// is what Excel produces on French Windows and a superset of ISO-8859-1.
+const fallbackEncoding = "windows-1252"
+
+var byteOrderMarks = []struct {
+	bom      []byte
+	encoding string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// toUTF8 transcodes b to NFC-normalized UTF-8.
+// A byte order mark always wins. Otherwise the explicit encoding label is
+// used (e.g. "windows-1252", "iso-8859-1"); when empty, valid UTF-8 is kept
+// as is and anything else is read as Windows-1252.
+func toUTF8(b []byte, encoding string) ([]byte, error) {
+	for _, m := range byteOrderMarks {
+		if bytes.HasPrefix(b, m.bom) {
+			return decode(b[len(m.bom):], m.encoding)
+		}
+	}
+
+	if encoding == "" {
+		encoding = detectEncoding(b)
+	}
+	return decode(b, encoding)
+}
+
+// detectEncoding guesses the encoding of a text without any declaration.
+func detectEncoding(b []byte) string {
+	if utf8.Valid(b) {
+		return "utf-8"
+	}
+	return fallbackEncoding
+}
+
+func decode(b []byte, encoding string) ([]byte, error) {
+	e, err := htmlindex.Get(encoding)
+	if err != nil {
+		return nil, fmt.Errorf("unknown encoding %q: %w", encoding, err)
+	}
+
+	out, err := e.NewDecoder().Bytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", encoding, err)
+	}
+
+	// Some tools write decomposed accents, which would break header lookups
+	return norm.NFC.Bytes(out), nil
+}
+
+// htmlToUTF8 transcodes an HTML document to UTF-8, honoring the charset
+// declared by a <meta> tag when no explicit encoding is given. A declared
+// UTF-8 charset on content that is not valid UTF-8 is ignored.
+func htmlToUTF8(b []byte, encoding string) ([]byte, error) {
+	if encoding == "" {
+		if e, err := htmlindex.Get(metaCharset(b)); err == nil {
+			if name, _ := htmlindex.Name(e); name != "utf-8" || utf8.Valid(b) {
+				encoding = name
+			}
+		}
+	}
+	return toUTF8(b, encoding)
+}
+
+// metaCharset returns the charset declared in the document head, either by
+// <meta charset> or <meta http-equiv="Content-Type" content="...; charset=...">.
+func metaCharset(b []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(b))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			switch t.DataAtom {
+			case atom.Meta:
+				var httpEquiv, content string
+				for _, a := range t.Attr {
+					switch strings.ToLower(a.Key) {
+					case "charset":
+						return strings.TrimSpace(a.Val)
+					case "http-equiv":
+						httpEquiv = strings.ToLower(a.Val)
+					case "content":
+						content = a.Val
+					}
+				}
+				if httpEquiv == "content-type" {
+					if _, after, ok := strings.Cut(strings.ToLower(content), "charset="); ok {
+						return strings.Trim(strings.TrimSpace(after), `"'`)
+					}
+				}
+			case atom.Body, atom.Table:
+				// Declarations only matter in the head
+				return ""
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+// "Hélène" in Windows-1252
+var heleneCP1252 = []byte{'H', 0xE9, 'l', 0xE8, 'n', 'e'}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		encoding string
+		want     string
+	}{
+		{"UTF-8 kept", []byte("Hélène"), "", "Hélène"},
+		{"UTF-8 BOM removed", append([]byte{0xEF, 0xBB, 0xBF}, "Hélène"...), "", "Hélène"},
+		{"UTF-16LE BOM", []byte{0xFF, 0xFE, 'H', 0, 0xE9, 0}, "", "Hé"},
+		{"Windows-1252 detected", heleneCP1252, "", "Hélène"},
+		{"Explicit ISO-8859-1", heleneCP1252, "iso-8859-1", "Hélène"},
+		{"Decomposed accents normalized", []byte("Hélène"), "", "Hélène"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toUTF8(tt.input, tt.encoding)
+			if err != nil {
+				t.Fatalf("toUTF8() error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("toUTF8() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToUTF8UnknownEncoding(t *testing.T) {
+	if _, err := toUTF8([]byte("x"), "klingon"); err == nil {
+		t.Fatal("expected an error for an unknown encoding")
+	}
+}
+
+func TestMetaCharset(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"meta charset", `<html><head><meta charset="ISO-8859-1"></head><body></body></html>`, "ISO-8859-1"},
+		{"http-equiv", `<html><head><meta http-equiv="Content-Type" content="text/html; charset=windows-1252"></head></html>`, "windows-1252"},
+		{"no declaration", `<html><body><table></table></body></html>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metaCharset([]byte(tt.html)); got != tt.want {
+				t.Errorf("metaCharset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromExcelHTMLReaderLatin1(t *testing.T) {
+	var doc bytes.Buffer
+	doc.WriteString(`<html><head><meta http-equiv="Content-Type" content="text/html; charset=iso-8859-1"></head><body><table><tbody>`)
+	doc.WriteString("<tr><td>Individu.Prenom</td><td>Individu.Courriel")
+	doc.Write([]byte{'D', 0xE9, 'd', 'i', 0xE9})
+	doc.WriteString("SGDF</td></tr><tr><td>")
+	doc.Write(heleneCP1252)
+	doc.WriteString("</td><td>helene@sgdf.fr</td></tr></tbody></table></body></html>")
+
+	rows, err := FromExcelHTMLReader(&doc)
+	if err != nil {
+		t.Fatalf("FromExcelHTMLReader failed: %v", err)
+	}
+
+	n := 0
+	for row := range rows {
+		if row["Individu.Prenom"] != "Hélène" {
+			t.Errorf("expected Hélène, got %q", row["Individu.Prenom"])
+		}
+		if row["Individu.CourrielDédiéSGDF"] != "helene@sgdf.fr" {
+			t.Errorf("expected header Individu.CourrielDédiéSGDF to be found, got row %v", row)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 row, got %d", n)
+	}
+}
+
+func TestFromCSVReaderEncoding(t *testing.T) {
+	input := append([]byte("First Name,Last Name\n"), heleneCP1252...)
+	input = append(input, ",Martin\n"...)
+
+	rows, err := FromCSVReader(bytes.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("FromCSVReader failed: %v", err)
+	}
+
+	for row := range rows {
+		if row["First Name"] != "Hélène" {
+			t.Errorf("expected Hélène, got %q", row["First Name"])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"iter"
@@ -14,9 +15,64 @@ import (
 // Format: header -> cell value
 type Row map[string]string
 
+var (
+	xlsxSignature = []byte("PK\x03\x04")
+	xlsSignature  = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+)
+
+// newRow maps a record onto the headers, naming extra cells extra_N.
+func newRow(headers, record []string) Row {
+	row := make(Row)
+	for i, value := range record {
+		if i < len(headers) {
+			row[headers[i]] = value
+		} else {
+			row[fmt.Sprintf("extra_%d", i-len(headers)+1)] = value
+		}
+	}
+	return row
+}
+
+// FromExcelReader parses an intranet export whatever its real format. The
+// intranet serves an HTML table with a .xls extension, but a file re-saved
+// from Excel or LibreOffice becomes a genuine XLSX or XLS (BIFF8) workbook:
+// the file signature decides which reader is used.
+// The encoding only applies to the HTML export, workbooks always carry Unicode
+// text; when empty it is detected.
+func FromExcelReader(r io.Reader, encoding string) (iter.Seq[Row], error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read excel: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(b, xlsxSignature):
+		return fromXLSX(b)
+	case bytes.HasPrefix(b, xlsSignature):
+		return fromXLS(b)
+	default:
+		return fromHTML(b, encoding)
+	}
+}
+
 // FromExcelHTMLReader parses an intranet export (Excel HTML) and returns rows.
+// The character encoding is taken from the byte order mark or the <meta>
+// charset declaration, and guessed otherwise.
 func FromExcelHTMLReader(r io.Reader) (iter.Seq[Row], error) {
-	doc, err := html.Parse(r)
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read html: %w", err)
+	}
+	return fromHTML(b, "")
+}
+
+func fromHTML(b []byte, encoding string) (iter.Seq[Row], error) {
+	b, err := htmlToUTF8(b, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("decode html: %w", err)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(b))
 	if err != nil {
 		return nil, fmt.Errorf("parse html: %w", err)
 	}
@@ -49,3 +49,19 @@ func TestParseFromExcelHTMLReader(t *testing.T) {
 	}
 
 }
+
+func TestParseFromExcelReaderHTML(t *testing.T) {
+	rows, err := FromExcelReader(strings.NewReader(sampleHTML), "")
+	if err != nil {
+		t.Fatalf("FromExcelReader failed: %v", err)
+	}
+
+	var names []string
+	for row := range rows {
+		names = append(names, row["Individu.Nom"])
+	}
+
+	if len(names) != 2 || names[0] != "Dupont" || names[1] != "Martin" {
+		t.Fatalf("expected [Dupont Martin], got %v", names)
+	}
+}
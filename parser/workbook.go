@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"strings"
+	"time"
+)
+
+// Builtin Excel number formats displaying a date
+var builtinDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 22: true,
+	27: true, 30: true, 36: true, 45: true, 46: true, 47: true,
+	50: true, 57: true,
+}
+
+// gridRows turns a worksheet grid into rows, the first line being the header.
+// Blank lines are skipped.
+func gridRows(grid [][]string) (iter.Seq[Row], error) {
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return nil, fmt.Errorf("no header row found in worksheet")
+	}
+	headers := grid[0]
+
+	return func(yield func(Row) bool) {
+		for _, record := range grid[1:] {
+			if isBlankRecord(record) {
+				continue
+			}
+			if !yield(newRow(headers, record)) {
+				return
+			}
+		}
+	}, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isDateFormat reports whether a number format displays a date, either by
+// its builtin identifier or by the tokens of its custom format code.
+func isDateFormat(id int, code string) bool {
+	if builtinDateFormats[id] {
+		return true
+	}
+	if code == "" {
+		return false
+	}
+
+	// Ignore quoted literals and bracketed sections (colors, locales)
+	var sb strings.Builder
+	inQuote, inBracket := false, false
+	for _, ch := range code {
+		switch {
+		case ch == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case ch == '[':
+			inBracket = true
+		case ch == ']':
+			inBracket = false
+		case !inBracket:
+			sb.WriteRune(ch)
+		}
+	}
+	return strings.ContainsAny(strings.ToLower(sb.String()), "dmyj")
+}
+
+// formatExcelDate converts an Excel serial date into the dd/mm/yyyy layout
+// used by the intranet HTML export.
+func formatExcelDate(serial float64, date1904 bool) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(serial)
+	return epoch.AddDate(0, 0, int(days)).Format("02/01/2006")
+}
@@ -0,0 +1,590 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"math"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Compound File Binary (OLE2) special sector numbers
+const (
+	cfbEndOfChain = 0xFFFFFFFE
+	cfbFreeSect   = 0xFFFFFFFF
+)
+
+// BIFF8 record identifiers used to extract cell values
+const (
+	biffFormula    = 0x0006
+	biffEOF        = 0x000A
+	biffDateMode   = 0x0022
+	biffContinue   = 0x003C
+	biffBoundSheet = 0x0085
+	biffMulRK      = 0x00BD
+	biffXF         = 0x00E0
+	biffSST        = 0x00FC
+	biffLabelSST   = 0x00FD
+	biffNumber     = 0x0203
+	biffLabel      = 0x0204
+	biffBoolErr    = 0x0205
+	biffString     = 0x0207
+	biffRK         = 0x027E
+	biffFormat     = 0x041E
+	biffBOF        = 0x0809
+)
+
+// fromXLS parses a legacy Excel 97-2003 workbook (BIFF8 inside an OLE2
+// compound file) and returns the rows of its first worksheet.
+func fromXLS(b []byte) (iter.Seq[Row], error) {
+	cfb, err := newCompoundFile(b)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := cfb.stream("Workbook")
+	if err != nil {
+		if _, berr := cfb.stream("Book"); berr == nil {
+			return nil, fmt.Errorf("xls: BIFF5 workbooks are not supported, re-save the file as .xlsx")
+		}
+		return nil, err
+	}
+
+	grid, err := biffFirstSheet(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return gridRows(grid)
+}
+
+type compoundFile struct {
+	data           []byte
+	sectorSize     int
+	miniSectorSize int
+	miniCutoff     uint32
+	fat            []uint32
+	miniFAT        []uint32
+	miniStream     []byte
+	entries        []cfbEntry
+}
+
+type cfbEntry struct {
+	name  string
+	kind  byte
+	start uint32
+	size  uint64
+}
+
+func newCompoundFile(b []byte) (*compoundFile, error) {
+	if len(b) < 512 {
+		return nil, fmt.Errorf("xls: file too short")
+	}
+
+	cfb := &compoundFile{
+		data:           b,
+		sectorSize:     1 << binary.LittleEndian.Uint16(b[0x1E:]),
+		miniSectorSize: 1 << binary.LittleEndian.Uint16(b[0x20:]),
+		miniCutoff:     binary.LittleEndian.Uint32(b[0x38:]),
+	}
+	if cfb.sectorSize != 512 && cfb.sectorSize != 4096 {
+		return nil, fmt.Errorf("xls: invalid sector size %d", cfb.sectorSize)
+	}
+
+	// Collect FAT sector numbers from the header DIFAT and the DIFAT chain.
+	// The count comes from the file: the file holds at most one FAT sector
+	// per sector
+	numFATSectors := min(int(binary.LittleEndian.Uint32(b[0x2C:])), len(b)/cfb.sectorSize)
+	var fatSectors []uint32
+	for i := 0; i < 109 && len(fatSectors) < numFATSectors; i++ {
+		fatSectors = append(fatSectors, binary.LittleEndian.Uint32(b[0x4C+4*i:]))
+	}
+	difat := binary.LittleEndian.Uint32(b[0x44:])
+	perSector := cfb.sectorSize/4 - 1
+	seen := make(map[uint32]bool)
+	for difat != cfbEndOfChain && difat != cfbFreeSect && len(fatSectors) < numFATSectors {
+		if seen[difat] {
+			return nil, fmt.Errorf("xls: corrupted DIFAT chain")
+		}
+		seen[difat] = true
+		sec, err := cfb.sector(difat)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < perSector && len(fatSectors) < numFATSectors; i++ {
+			fatSectors = append(fatSectors, binary.LittleEndian.Uint32(sec[4*i:]))
+		}
+		difat = binary.LittleEndian.Uint32(sec[4*perSector:])
+	}
+
+	for _, s := range fatSectors {
+		sec, err := cfb.sector(s)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < cfb.sectorSize; i += 4 {
+			cfb.fat = append(cfb.fat, binary.LittleEndian.Uint32(sec[i:]))
+		}
+	}
+
+	dir, err := cfb.chain(binary.LittleEndian.Uint32(b[0x30:]), 0)
+	if err != nil {
+		return nil, fmt.Errorf("xls: read directory: %w", err)
+	}
+	for off := 0; off+128 <= len(dir); off += 128 {
+		e := dir[off : off+128]
+		nameLen := int(binary.LittleEndian.Uint16(e[0x40:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+		var name []uint16
+		for i := 0; i+1 < nameLen-1; i += 2 {
+			name = append(name, binary.LittleEndian.Uint16(e[i:]))
+		}
+		cfb.entries = append(cfb.entries, cfbEntry{
+			name:  string(utf16.Decode(name)),
+			kind:  e[0x42],
+			start: binary.LittleEndian.Uint32(e[0x74:]),
+			size:  binary.LittleEndian.Uint64(e[0x78:]),
+		})
+	}
+	if len(cfb.entries) == 0 || cfb.entries[0].kind != 5 {
+		return nil, fmt.Errorf("xls: missing root directory entry")
+	}
+
+	// Small streams live in the mini stream, addressed through the mini FAT
+	miniFAT, err := cfb.chain(binary.LittleEndian.Uint32(b[0x3C:]), 0)
+	if err != nil {
+		return nil, fmt.Errorf("xls: read mini FAT: %w", err)
+	}
+	for i := 0; i+4 <= len(miniFAT); i += 4 {
+		cfb.miniFAT = append(cfb.miniFAT, binary.LittleEndian.Uint32(miniFAT[i:]))
+	}
+	root := cfb.entries[0]
+	cfb.miniStream, err = cfb.chain(root.start, root.size)
+	if err != nil {
+		return nil, fmt.Errorf("xls: read mini stream: %w", err)
+	}
+
+	return cfb, nil
+}
+
+func (cfb *compoundFile) sector(n uint32) ([]byte, error) {
+	off := (int(n) + 1) * cfb.sectorSize
+	if n >= cfbEndOfChain || off+cfb.sectorSize > len(cfb.data) {
+		return nil, fmt.Errorf("xls: sector %d out of range", n)
+	}
+	return cfb.data[off : off+cfb.sectorSize], nil
+}
+
+// chain reads a stream stored in regular sectors. A zero size reads the whole chain.
+func (cfb *compoundFile) chain(start uint32, size uint64) ([]byte, error) {
+	var out []byte
+	seen := make(map[uint32]bool)
+	for s := start; s != cfbEndOfChain && s != cfbFreeSect; {
+		if seen[s] || int(s) >= len(cfb.fat) {
+			return nil, fmt.Errorf("xls: corrupted sector chain")
+		}
+		seen[s] = true
+		sec, err := cfb.sector(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sec...)
+		s = cfb.fat[s]
+	}
+	if size > 0 && uint64(len(out)) > size {
+		out = out[:size]
+	}
+	return out, nil
+}
+
+func (cfb *compoundFile) miniChain(start uint32, size uint64) ([]byte, error) {
+	var out []byte
+	seen := make(map[uint32]bool)
+	for s := start; s != cfbEndOfChain && s != cfbFreeSect; {
+		off := int(s) * cfb.miniSectorSize
+		if seen[s] || int(s) >= len(cfb.miniFAT) || off+cfb.miniSectorSize > len(cfb.miniStream) {
+			return nil, fmt.Errorf("xls: corrupted mini sector chain")
+		}
+		seen[s] = true
+		out = append(out, cfb.miniStream[off:off+cfb.miniSectorSize]...)
+		s = cfb.miniFAT[s]
+	}
+	if uint64(len(out)) > size {
+		out = out[:size]
+	}
+	return out, nil
+}
+
+func (cfb *compoundFile) stream(name string) ([]byte, error) {
+	for _, e := range cfb.entries[1:] {
+		if e.kind != 2 || e.name != name {
+			continue
+		}
+		if e.size < uint64(cfb.miniCutoff) {
+			return cfb.miniChain(e.start, e.size)
+		}
+		return cfb.chain(e.start, e.size)
+	}
+	return nil, fmt.Errorf("xls: no %s stream found", name)
+}
+
+type biffRecord struct {
+	id   uint16
+	data []byte
+}
+
+// biffRecords splits a BIFF stream into records, starting at offset.
+func biffRecords(stream []byte, offset int) iter.Seq[biffRecord] {
+	return func(yield func(biffRecord) bool) {
+		for off := offset; off+4 <= len(stream); {
+			id := binary.LittleEndian.Uint16(stream[off:])
+			size := int(binary.LittleEndian.Uint16(stream[off+2:]))
+			end := min(off+4+size, len(stream))
+			if !yield(biffRecord{id: id, data: stream[off+4 : end]}) {
+				return
+			}
+			off = end
+		}
+	}
+}
+
+// biffFirstSheet reads the workbook globals (shared strings, formats) then
+// the cells of the first worksheet.
+func biffFirstSheet(stream []byte) ([][]string, error) {
+	var (
+		sstSegments [][]byte
+		inSST       bool
+		formats     = make(map[int]string)
+		xfFormats   []int
+		sheetOffset = -1
+		date1904    bool
+	)
+
+	for rec := range biffRecords(stream, 0) {
+		if rec.id != biffContinue {
+			inSST = false
+		}
+		switch rec.id {
+		case biffBOF:
+			if len(rec.data) >= 4 && binary.LittleEndian.Uint16(rec.data) != 0x0600 {
+				return nil, fmt.Errorf("xls: only BIFF8 workbooks are supported, re-save the file as .xlsx")
+			}
+		case biffSST:
+			sstSegments = [][]byte{rec.data}
+			inSST = true
+		case biffContinue:
+			if inSST {
+				sstSegments = append(sstSegments, rec.data)
+			}
+		case biffFormat:
+			if len(rec.data) >= 2 {
+				r := &biffReader{segments: [][]byte{rec.data[2:]}}
+				if s, err := r.unicodeString(2); err == nil {
+					formats[int(binary.LittleEndian.Uint16(rec.data))] = s
+				}
+			}
+		case biffXF:
+			if len(rec.data) >= 4 {
+				xfFormats = append(xfFormats, int(binary.LittleEndian.Uint16(rec.data[2:])))
+			}
+		case biffDateMode:
+			date1904 = len(rec.data) >= 2 && binary.LittleEndian.Uint16(rec.data) == 1
+		case biffBoundSheet:
+			// Keep the first worksheet (type 0), skipping charts and macros
+			if sheetOffset < 0 && len(rec.data) >= 6 && rec.data[5] == 0 {
+				sheetOffset = int(binary.LittleEndian.Uint32(rec.data))
+			}
+		}
+		if rec.id == biffEOF {
+			break
+		}
+	}
+
+	if sheetOffset < 0 || sheetOffset >= len(stream) {
+		return nil, fmt.Errorf("xls: workbook has no worksheet")
+	}
+
+	var sst []string
+	if len(sstSegments) > 0 {
+		var err error
+		if sst, err = parseSST(sstSegments); err != nil {
+			return nil, err
+		}
+	}
+
+	isDate := func(xf int) bool {
+		if xf < 0 || xf >= len(xfFormats) {
+			return false
+		}
+		return isDateFormat(xfFormats[xf], formats[xfFormats[xf]])
+	}
+	number := func(xf int, v float64) string {
+		if isDate(xf) {
+			return formatExcelDate(v, date1904)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	var grid [][]string
+	set := func(row, col int, value string) {
+		for len(grid) <= row {
+			grid = append(grid, nil)
+		}
+		for len(grid[row]) <= col {
+			grid[row] = append(grid[row], "")
+		}
+		grid[row][col] = value
+	}
+
+	pendingFormula := [2]int{-1, -1}
+	for rec := range biffRecords(stream, sheetOffset) {
+		d := rec.data
+		if rec.id == biffEOF {
+			break
+		}
+		if rec.id != biffString && rec.id != biffContinue {
+			pendingFormula = [2]int{-1, -1}
+		}
+		if len(d) < 6 && rec.id != biffString {
+			continue
+		}
+
+		switch rec.id {
+		case biffLabelSST:
+			if len(d) < 10 {
+				continue
+			}
+			idx := int(binary.LittleEndian.Uint32(d[6:]))
+			if idx >= len(sst) {
+				return nil, fmt.Errorf("xls: invalid shared string index %d", idx)
+			}
+			set(int(binary.LittleEndian.Uint16(d)), int(binary.LittleEndian.Uint16(d[2:])), sst[idx])
+		case biffLabel:
+			r := &biffReader{segments: [][]byte{d[6:]}}
+			s, err := r.unicodeString(2)
+			if err != nil {
+				return nil, err
+			}
+			set(int(binary.LittleEndian.Uint16(d)), int(binary.LittleEndian.Uint16(d[2:])), s)
+		case biffNumber:
+			if len(d) < 14 {
+				continue
+			}
+			v := math.Float64frombits(binary.LittleEndian.Uint64(d[6:]))
+			set(int(binary.LittleEndian.Uint16(d)), int(binary.LittleEndian.Uint16(d[2:])), number(int(binary.LittleEndian.Uint16(d[4:])), v))
+		case biffRK:
+			if len(d) < 10 {
+				continue
+			}
+			v := rkValue(binary.LittleEndian.Uint32(d[6:]))
+			set(int(binary.LittleEndian.Uint16(d)), int(binary.LittleEndian.Uint16(d[2:])), number(int(binary.LittleEndian.Uint16(d[4:])), v))
+		case biffMulRK:
+			row := int(binary.LittleEndian.Uint16(d))
+			col := int(binary.LittleEndian.Uint16(d[2:]))
+			for off := 4; off+6 <= len(d)-2; off += 6 {
+				xf := int(binary.LittleEndian.Uint16(d[off:]))
+				set(row, col, number(xf, rkValue(binary.LittleEndian.Uint32(d[off+2:]))))
+				col++
+			}
+		case biffBoolErr:
+			if len(d) < 8 {
+				continue
+			}
+			value := "#ERR"
+			if d[7] == 0 {
+				value = "FALSE"
+				if d[6] != 0 {
+					value = "TRUE"
+				}
+			}
+			set(int(binary.LittleEndian.Uint16(d)), int(binary.LittleEndian.Uint16(d[2:])), value)
+		case biffFormula:
+			if len(d) < 14 {
+				continue
+			}
+			row, col := int(binary.LittleEndian.Uint16(d)), int(binary.LittleEndian.Uint16(d[2:]))
+			result := d[6:14]
+			if binary.LittleEndian.Uint16(result[6:]) != 0xFFFF {
+				v := math.Float64frombits(binary.LittleEndian.Uint64(result))
+				set(row, col, number(int(binary.LittleEndian.Uint16(d[4:])), v))
+				continue
+			}
+			switch result[0] {
+			case 0: // String result, value follows in a STRING record
+				pendingFormula = [2]int{row, col}
+			case 1:
+				value := "FALSE"
+				if result[2] != 0 {
+					value = "TRUE"
+				}
+				set(row, col, value)
+			}
+		case biffString:
+			if pendingFormula[0] < 0 {
+				continue
+			}
+			r := &biffReader{segments: [][]byte{d}}
+			s, err := r.unicodeString(2)
+			if err != nil {
+				return nil, err
+			}
+			set(pendingFormula[0], pendingFormula[1], s)
+			pendingFormula = [2]int{-1, -1}
+		}
+	}
+
+	return grid, nil
+}
+
+// rkValue decodes the compact RK number representation.
+func rkValue(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// parseSST decodes the shared string table, whose strings may be split
+// across CONTINUE records.
+func parseSST(segments [][]byte) ([]string, error) {
+	r := &biffReader{segments: segments}
+	if _, err := r.bytes(4); err != nil { // Total number of strings in the workbook
+		return nil, err
+	}
+	b, err := r.bytes(4)
+	if err != nil {
+		return nil, err
+	}
+	unique := int(binary.LittleEndian.Uint32(b))
+
+	// The count comes from the file: each string takes at least 3 bytes, so
+	// the remaining bytes bound the allocation of a corrupt table
+	strs := make([]string, 0, min(unique, r.remaining()/3))
+	for range unique {
+		s, err := r.unicodeString(2)
+		if err != nil {
+			return nil, fmt.Errorf("xls: read shared strings: %w", err)
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+// biffReader reads a record payload possibly continued over several
+// CONTINUE records.
+type biffReader struct {
+	segments [][]byte
+	seg, off int
+}
+
+func (r *biffReader) next() bool {
+	if r.seg+1 >= len(r.segments) {
+		return false
+	}
+	r.seg++
+	r.off = 0
+	return true
+}
+
+// remaining returns the number of bytes left to read.
+func (r *biffReader) remaining() int {
+	n := 0
+	for i := r.seg; i < len(r.segments); i++ {
+		n += len(r.segments[i])
+	}
+	return n - r.off
+}
+
+func (r *biffReader) bytes(n int) ([]byte, error) {
+	var out []byte
+	for n > 0 {
+		cur := r.segments[r.seg]
+		if r.off >= len(cur) {
+			if !r.next() {
+				return nil, fmt.Errorf("unexpected end of record")
+			}
+			continue
+		}
+		k := min(n, len(cur)-r.off)
+		out = append(out, cur[r.off:r.off+k]...)
+		r.off += k
+		n -= k
+	}
+	return out, nil
+}
+
+// unicodeString reads an XLUnicodeRichExtendedString whose character count
+// is encoded on lenSize bytes.
+func (r *biffReader) unicodeString(lenSize int) (string, error) {
+	b, err := r.bytes(lenSize + 1)
+	if err != nil {
+		return "", err
+	}
+	var cch int
+	if lenSize == 1 {
+		cch = int(b[0])
+	} else {
+		cch = int(binary.LittleEndian.Uint16(b))
+	}
+	flags := b[lenSize]
+	highByte := flags&0x01 != 0
+
+	var runs, extSize int
+	if flags&0x08 != 0 {
+		b, err := r.bytes(2)
+		if err != nil {
+			return "", err
+		}
+		runs = int(binary.LittleEndian.Uint16(b))
+	}
+	if flags&0x04 != 0 {
+		b, err := r.bytes(4)
+		if err != nil {
+			return "", err
+		}
+		extSize = int(binary.LittleEndian.Uint32(b))
+	}
+
+	chars := make([]uint16, 0, cch)
+	for len(chars) < cch {
+		cur := r.segments[r.seg]
+		if r.off >= len(cur) {
+			// Character data continued in a new record restates its compression flag
+			if !r.next() {
+				return "", fmt.Errorf("unexpected end of string")
+			}
+			flag, err := r.bytes(1)
+			if err != nil {
+				return "", err
+			}
+			highByte = flag[0]&0x01 != 0
+			continue
+		}
+		if highByte {
+			if r.off+2 > len(cur) {
+				return "", fmt.Errorf("truncated character")
+			}
+			chars = append(chars, binary.LittleEndian.Uint16(cur[r.off:]))
+			r.off += 2
+		} else {
+			// Compressed strings hold the low byte of each UTF-16 code unit
+			chars = append(chars, uint16(cur[r.off]))
+			r.off++
+		}
+	}
+
+	if _, err := r.bytes(4*runs + extSize); err != nil {
+		return "", err
+	}
+
+	return string(utf16.Decode(chars)), nil
+}
@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"unicode/utf16"
+)
+
+func biffRec(id uint16, data []byte) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, id)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
+
+func biffCell(row, col, xf uint16, rest ...byte) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, row)
+	b = binary.LittleEndian.AppendUint16(b, col)
+	b = binary.LittleEndian.AppendUint16(b, xf)
+	return append(b, rest...)
+}
+
+// compressedString encodes an XLUnicodeString with 8-bit characters.
+func compressedString(s string) []byte {
+	runes := []rune(s)
+	b := binary.LittleEndian.AppendUint16(nil, uint16(len(runes)))
+	b = append(b, 0)
+	for _, r := range runes {
+		b = append(b, byte(r))
+	}
+	return b
+}
+
+func bof(kind uint16) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, 0x0600)
+	b = binary.LittleEndian.AppendUint16(b, kind)
+	return biffRec(biffBOF, append(b, make([]byte, 12)...))
+}
+
+func buildWorkbookStream() []byte {
+	var globals []byte
+	globals = append(globals, bof(0x0005)...)
+	globals = append(globals, biffRec(biffFormat, append([]byte{164, 0}, compressedString("dd/mm/yyyy")...))...)
+	globals = append(globals, biffRec(biffXF, []byte{0, 0, 0, 0, 0, 0})...)
+	globals = append(globals, biffRec(biffXF, []byte{0, 0, 164, 0, 0, 0})...)
+
+	// Shared strings, the last one split across a CONTINUE record in UTF-16
+	sst := binary.LittleEndian.AppendUint32(nil, 6)
+	sst = binary.LittleEndian.AppendUint32(sst, 5)
+	for _, s := range []string{"Individu.Nom", "Individu.Prenom", "Individu.DateNaissance", "Dupont"} {
+		sst = append(sst, compressedString(s)...)
+	}
+	sst = append(sst, 6, 0, 0) // "Hélène": 6 chars, compressed
+	sst = append(sst, 'H', 0xE9, 'l')
+	cont := []byte{1} // Continued with 16-bit characters
+	for _, u := range utf16.Encode([]rune("ène")) {
+		cont = binary.LittleEndian.AppendUint16(cont, u)
+	}
+
+	boundSheetPos := len(globals)
+	boundSheet := append(make([]byte, 6), byte(len("Individus")), 0)
+	boundSheet = append(boundSheet, "Individus"...)
+	globals = append(globals, biffRec(biffBoundSheet, boundSheet)...)
+	globals = append(globals, biffRec(biffSST, sst)...)
+	globals = append(globals, biffRec(biffContinue, cont)...)
+	globals = append(globals, biffRec(biffEOF, nil)...)
+
+	binary.LittleEndian.PutUint32(globals[boundSheetPos+4:], uint32(len(globals)))
+
+	var sheet []byte
+	sheet = append(sheet, bof(0x0010)...)
+	for col := range 3 {
+		sheet = append(sheet, biffRec(biffLabelSST, biffCell(0, uint16(col), 0, byte(col), 0, 0, 0))...)
+	}
+	sheet = append(sheet, biffRec(biffLabel, biffCell(0, 3, 0, compressedString("Age")...))...)
+	sheet = append(sheet, biffRec(biffLabelSST, biffCell(1, 0, 0, 3, 0, 0, 0))...)
+	sheet = append(sheet, biffRec(biffLabelSST, biffCell(1, 1, 0, 4, 0, 0, 0))...)
+	date := binary.LittleEndian.AppendUint64(nil, math.Float64bits(40179))
+	sheet = append(sheet, biffRec(biffNumber, biffCell(1, 2, 1, date...))...)
+	sheet = append(sheet, biffRec(biffRK, biffCell(1, 3, 0, binary.LittleEndian.AppendUint32(nil, 30<<2|2)...))...)
+	sheet = append(sheet, biffRec(biffLabel, biffCell(2, 0, 0, compressedString("Martin")...))...)
+	mulrk := binary.LittleEndian.AppendUint16(nil, 2)
+	mulrk = binary.LittleEndian.AppendUint16(mulrk, 3)
+	mulrk = binary.LittleEndian.AppendUint16(mulrk, 0)
+	mulrk = binary.LittleEndian.AppendUint32(mulrk, 2500<<2|3) // 25.00 as an integer divided by 100
+	mulrk = binary.LittleEndian.AppendUint16(mulrk, 3)
+	sheet = append(sheet, biffRec(biffMulRK, mulrk)...)
+	sheet = append(sheet, biffRec(biffEOF, nil)...)
+
+	return append(globals, sheet...)
+}
+
+// buildCompoundFile wraps a stream in a minimal OLE2 container using 512
+// bytes sectors: FAT in sector 0, directory in sector 1, stream afterwards.
+func buildCompoundFile(name string, stream []byte) []byte {
+	const sectorSize = 512
+	if len(stream) < 4096 {
+		stream = append(stream, make([]byte, 4096-len(stream))...)
+	}
+	streamSectors := (len(stream) + sectorSize - 1) / sectorSize
+	stream = append(stream, make([]byte, streamSectors*sectorSize-len(stream))...)
+
+	header := make([]byte, sectorSize)
+	copy(header, xlsSignature)
+	binary.LittleEndian.PutUint16(header[0x18:], 0x3E)
+	binary.LittleEndian.PutUint16(header[0x1A:], 3)
+	binary.LittleEndian.PutUint16(header[0x1C:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[0x1E:], 9)
+	binary.LittleEndian.PutUint16(header[0x20:], 6)
+	binary.LittleEndian.PutUint32(header[0x2C:], 1)
+	binary.LittleEndian.PutUint32(header[0x30:], 1)
+	binary.LittleEndian.PutUint32(header[0x38:], 4096)
+	binary.LittleEndian.PutUint32(header[0x3C:], cfbEndOfChain)
+	binary.LittleEndian.PutUint32(header[0x44:], cfbEndOfChain)
+	for i := range 109 {
+		binary.LittleEndian.PutUint32(header[0x4C+4*i:], cfbFreeSect)
+	}
+	binary.LittleEndian.PutUint32(header[0x4C:], 0)
+
+	fat := make([]byte, sectorSize)
+	for i := range sectorSize / 4 {
+		binary.LittleEndian.PutUint32(fat[4*i:], cfbFreeSect)
+	}
+	binary.LittleEndian.PutUint32(fat[0:], 0xFFFFFFFD)
+	binary.LittleEndian.PutUint32(fat[4:], cfbEndOfChain)
+	for i := range streamSectors {
+		next := uint32(i + 3)
+		if i == streamSectors-1 {
+			next = cfbEndOfChain
+		}
+		binary.LittleEndian.PutUint32(fat[4*(i+2):], next)
+	}
+
+	dirEntry := func(name string, kind byte, start uint32, size uint64) []byte {
+		e := make([]byte, 128)
+		u := utf16.Encode([]rune(name))
+		for i, c := range u {
+			binary.LittleEndian.PutUint16(e[2*i:], c)
+		}
+		binary.LittleEndian.PutUint16(e[0x40:], uint16(2*len(u)+2))
+		e[0x42] = kind
+		binary.LittleEndian.PutUint32(e[0x74:], start)
+		binary.LittleEndian.PutUint64(e[0x78:], size)
+		return e
+	}
+	dir := dirEntry("Root Entry", 5, cfbEndOfChain, 0)
+	dir = append(dir, dirEntry(name, 2, 2, uint64(len(stream)))...)
+	dir = append(dir, make([]byte, 256)...)
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.Write(fat)
+	buf.Write(dir)
+	buf.Write(stream)
+	return buf.Bytes()
+}
+
+func TestParseFromExcelReaderXLS(t *testing.T) {
+	data := buildCompoundFile("Workbook", buildWorkbookStream())
+
+	rows, err := FromExcelReader(bytes.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("FromExcelReader failed: %v", err)
+	}
+
+	var got []Row
+	for row := range rows {
+		got = append(got, row)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got))
+	}
+
+	want := []Row{
+		{"Individu.Nom": "Dupont", "Individu.Prenom": "Hélène", "Individu.DateNaissance": "01/01/2010", "Age": "30"},
+		{"Individu.Nom": "Martin", "Age": "25"},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if got[i][k] != v {
+				t.Errorf("row %d: expected %s=%q, got %q", i, k, v, got[i][k])
+			}
+		}
+	}
+}
+
+func TestParseFromExcelReaderXLSMissingWorkbook(t *testing.T) {
+	data := buildCompoundFile("Other", []byte{})
+
+	if _, err := FromExcelReader(bytes.NewReader(data), ""); err == nil {
+		t.Fatal("expected an error for a compound file without workbook")
+	}
+}
+
+func TestRKValue(t *testing.T) {
+	tests := []struct {
+		rk   uint32
+		want float64
+	}{
+		{30<<2 | 2, 30},
+		{2500<<2 | 3, 25},
+		{uint32(math.Float64bits(1.5) >> 32), 1.5},
+	}
+
+	for _, tt := range tests {
+		if got := rkValue(tt.rk); got != tt.want {
+			t.Errorf("rkValue(%#x) = %v, want %v", tt.rk, got, tt.want)
+		}
+	}
+}
+
+func TestParseSSTCorruptCount(t *testing.T) {
+	// A count of 4 billion strings over a few bytes must fail, not allocate
+	sst := binary.LittleEndian.AppendUint32(nil, 0xFFFFFFFF)
+	sst = binary.LittleEndian.AppendUint32(sst, 0xFFFFFFFF)
+	sst = append(sst, compressedString("Nom")...)
+	if _, err := parseSST([][]byte{sst}); err == nil {
+		t.Error("expected an error for a truncated shared strings table")
+	}
+}
+
+func TestNewCompoundFileDIFATCycle(t *testing.T) {
+	// A DIFAT sector pointing to itself, with a huge FAT sector count
+	data := buildCompoundFile("Workbook", buildWorkbookStream())
+	binary.LittleEndian.PutUint32(data[0x2C:], 20_000_000)
+	binary.LittleEndian.PutUint32(data[0x44:], 2)
+	binary.LittleEndian.PutUint32(data[3*512+508:], 2)
+	if _, err := newCompoundFile(data); err == nil {
+		t.Error("expected an error for a DIFAT chain cycle")
+	}
+}
@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"iter"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// fromXLSX parses an Office Open XML workbook (zip + XML) and returns the
+// rows of its first worksheet.
+func fromXLSX(b []byte) (iter.Seq[Row], error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("open xlsx: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, date1904, err := xlsxFirstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sst, err := xlsxSharedStrings(files)
+	if err != nil {
+		return nil, err
+	}
+
+	dateStyles, err := xlsxDateStyles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	grid, err := xlsxSheet(files, sheetPath, sst, dateStyles, date1904)
+	if err != nil {
+		return nil, err
+	}
+
+	return gridRows(grid)
+}
+
+func decodeZipXML(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("xlsx: missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("xlsx: open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: decode %s: %w", name, err)
+	}
+	return nil
+}
+
+// xlsxFirstSheet resolves the path of the first worksheet declared in the
+// workbook, following the workbook relationships.
+func xlsxFirstSheet(files map[string]*zip.File) (string, bool, error) {
+	var wb struct {
+		WorkbookPr struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(files, "xl/workbook.xml", &wb); err != nil {
+		return "", false, err
+	}
+	date1904 := wb.WorkbookPr.Date1904 == "1" || wb.WorkbookPr.Date1904 == "true"
+
+	if len(wb.Sheets) == 0 {
+		return "", false, fmt.Errorf("xlsx: workbook has no sheet")
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipXML(files, "xl/_rels/workbook.xml.rels", &rels); err == nil {
+		for _, rel := range rels.Relationships {
+			if rel.ID != wb.Sheets[0].RID {
+				continue
+			}
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), date1904, nil
+			}
+			return path.Join("xl", rel.Target), date1904, nil
+		}
+	}
+
+	// Fall back on the conventional location
+	return "xl/worksheets/sheet1.xml", date1904, nil
+}
+
+// xlsxRichText is the content of a shared or inline string: either a plain
+// <t> element or a list of formatted runs.
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.T
+	}
+	var sb strings.Builder
+	for _, r := range rt.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+func xlsxSharedStrings(files map[string]*zip.File) ([]string, error) {
+	if _, ok := files["xl/sharedStrings.xml"]; !ok {
+		return nil, nil // Workbooks with inline strings only have no table
+	}
+
+	var sst struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	if err := decodeZipXML(files, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		strs[i] = si.String()
+	}
+	return strs, nil
+}
+
+// xlsxDateStyles returns, for each cell style index, whether it displays a date.
+func xlsxDateStyles(files map[string]*zip.File) ([]bool, error) {
+	if _, ok := files["xl/styles.xml"]; !ok {
+		return nil, nil
+	}
+
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decodeZipXML(files, "xl/styles.xml", &styles); err != nil {
+		return nil, err
+	}
+
+	custom := make(map[int]string, len(styles.NumFmts))
+	for _, nf := range styles.NumFmts {
+		custom[nf.ID] = nf.Code
+	}
+
+	dates := make([]bool, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		dates[i] = isDateFormat(xf.NumFmtID, custom[xf.NumFmtID])
+	}
+	return dates, nil
+}
+
+// Excel limits of a worksheet, bounding the grid built from the row and
+// cell references of a corrupt file
+const (
+	xlsxMaxRows    = 1 << 20
+	xlsxMaxColumns = 1 << 14
+)
+
+func xlsxSheet(files map[string]*zip.File, name string, sst []string, dateStyles []bool, date1904 bool) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string       `xml:"r,attr"`
+				Type   string       `xml:"t,attr"`
+				Style  int          `xml:"s,attr"`
+				Value  string       `xml:"v"`
+				Inline xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(files, name, &sheet); err != nil {
+		return nil, err
+	}
+
+	var grid [][]string
+	for i, r := range sheet.Rows {
+		rowIndex := i
+		if r.R > 0 {
+			rowIndex = r.R - 1
+		}
+		if rowIndex >= xlsxMaxRows {
+			return nil, fmt.Errorf("xlsx: row %d out of range", r.R)
+		}
+
+		var record []string
+		for j, c := range r.Cells {
+			col := j
+			if c.Ref != "" {
+				if ci, ok := columnIndex(c.Ref); ok {
+					col = ci
+				}
+			}
+			if col >= xlsxMaxColumns {
+				return nil, fmt.Errorf("xlsx: cell %s out of range", c.Ref)
+			}
+
+			var value string
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(sst) {
+					return nil, fmt.Errorf("xlsx: invalid shared string index %q in %s", c.Value, c.Ref)
+				}
+				value = sst[idx]
+			case "inlineStr":
+				value = c.Inline.String()
+			case "b":
+				value = strings.ToUpper(strconv.FormatBool(c.Value == "1"))
+			case "str", "e":
+				value = c.Value
+			default:
+				value = c.Value
+				if c.Style >= 0 && c.Style < len(dateStyles) && dateStyles[c.Style] {
+					if f, err := strconv.ParseFloat(c.Value, 64); err == nil {
+						value = formatExcelDate(f, date1904)
+					}
+				}
+			}
+
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			record[col] = value
+		}
+
+		for len(grid) <= rowIndex {
+			grid = append(grid, nil)
+		}
+		grid[rowIndex] = record
+	}
+
+	return grid, nil
+}
+
+// columnIndex converts the column part of a cell reference ("AB12") into a
+// zero-based index.
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		if col <= xlsxMaxColumns { // Past the limit, the index stays out of range
+			col = col*26 + int(ch-'A'+1)
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
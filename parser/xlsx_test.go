@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"maps"
+	"testing"
+)
+
+func buildXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+var sampleXLSX = map[string]string{
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets><sheet name="Individus" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/individus.xml"/>
+</Relationships>`,
+	"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>Individu.Nom</t></si>
+  <si><t>Individu.Prenom</t></si>
+  <si><t>Individu.DateNaissance</t></si>
+  <si><t>Dupont</t></si>
+  <si><r><t>Hél</t></r><r><t>ène</t></r></si>
+</sst>`,
+	"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <numFmts><numFmt numFmtId="164" formatCode="dd/mm/yyyy"/></numFmts>
+  <cellXfs><xf numFmtId="0"/><xf numFmtId="164"/></cellXfs>
+</styleSheet>`,
+	"xl/worksheets/individus.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="inlineStr"><is><t>Age</t></is></c></row>
+    <row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2" t="s"><v>4</v></c><c r="C2" s="1"><v>40179</v></c><c r="D2"><v>30</v></c></row>
+    <row r="4"><c r="A4" t="inlineStr"><is><t>Martin</t></is></c><c r="D4"><v>25</v></c></row>
+  </sheetData>
+</worksheet>`,
+}
+
+func TestParseFromExcelReaderXLSX(t *testing.T) {
+	rows, err := FromExcelReader(bytes.NewReader(buildXLSX(t, sampleXLSX)), "")
+	if err != nil {
+		t.Fatalf("FromExcelReader failed: %v", err)
+	}
+
+	var got []Row
+	for row := range rows {
+		got = append(got, row)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got))
+	}
+
+	want := []Row{
+		{"Individu.Nom": "Dupont", "Individu.Prenom": "Hélène", "Individu.DateNaissance": "01/01/2010", "Age": "30"},
+		{"Individu.Nom": "Martin", "Age": "25"},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if got[i][k] != v {
+				t.Errorf("row %d: expected %s=%q, got %q", i, k, v, got[i][k])
+			}
+		}
+	}
+	if got[1]["Individu.Prenom"] != "" {
+		t.Errorf("row 1: expected empty Individu.Prenom, got %q", got[1]["Individu.Prenom"])
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want int
+	}{
+		{"A1", 0},
+		{"Z9", 25},
+		{"AA10", 26},
+		{"AB3", 27},
+		{"XFD1", 16383},
+	}
+
+	for _, tt := range tests {
+		got, ok := columnIndex(tt.ref)
+		if !ok || got != tt.want {
+			t.Errorf("columnIndex(%q) = %d, %t; want %d", tt.ref, got, ok, tt.want)
+		}
+	}
+}
+
+func TestParseFromExcelReaderXLSXOutOfRange(t *testing.T) {
+	tests := []struct {
+		name string
+		row  string
+	}{
+		{"row", `<row r="2000000"><c r="A2000000" t="inlineStr"><is><t>Dupont</t></is></c></row>`},
+		{"column", `<row r="1"><c r="AAAAAAA1" t="inlineStr"><is><t>Dupont</t></is></c></row>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := maps.Clone(sampleXLSX)
+			files["xl/worksheets/individus.xml"] = `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>` + tt.row + `</sheetData>
+</worksheet>`
+			if _, err := FromExcelReader(bytes.NewReader(buildXLSX(t, files)), ""); err == nil {
+				t.Error("expected an error for a cell out of the worksheet limits")
+			}
+		})
+	}
+}
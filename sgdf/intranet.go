@@ -46,6 +46,9 @@ func ExtractIntranetContact(row parser.Row) ([]contact.Contact, error) {
 				// no specific label
 			}
 
+			legalGuardianContact.AddRelation(contact.RelationChild, mainContact)
+			contacts[0].AddRelation(contact.RelationParent, legalGuardianContact)
+
 			contacts = append(contacts, *legalGuardianContact)
 		}
 	}
@@ -87,38 +90,42 @@ func extractIntranetMainContact(row parser.Row) (*contact.Contact, error) {
 		}
 	}
 
+	var a contact.Address
 	if v, ok := row["Individu.Adresse.Ligne1"]; ok {
-		c.Address = address.FormatLine(v)
+		a.Street = address.FormatLine(v)
 	}
 
 	if v, ok := row["Individu.Adresse.Ligne2"]; ok {
-		if c.Address != "" && v != "" {
-			c.Address += "\n"
+		if a.Street != "" && v != "" {
+			a.Street += "\n"
 		}
-		if v != c.Address && v != "" {
-			c.Address += address.FormatLine(v)
+		if v != a.Street && v != "" {
+			a.Street += address.FormatLine(v)
 		}
 	}
 
 	if v, ok := row["Individu.Adresse.Ligne3"]; ok {
-		if c.Address != "" && v != "" {
-			c.Address += "\n"
+		if a.Street != "" && v != "" {
+			a.Street += "\n"
 		}
-		if v != c.Address && v != "" {
-			c.Address += address.FormatLine(v)
+		if v != a.Street && v != "" {
+			a.Street += address.FormatLine(v)
 		}
 	}
 
 	if v, ok := row["Individu.Adresse.CodePostal"]; ok {
-		c.ZipCode = v
+		a.ZipCode = v
 	}
 
 	if v, ok := row["Individu.Adresse.Municipalite"]; ok {
-		c.City = capitalizer.String(v)
+		a.City = capitalizer.String(v)
 	}
 
 	if v, ok := row["Individu.Adresse.Pays"]; ok {
-		c.Country = capitalizer.String(v)
+		a.Country = capitalizer.String(v)
+	}
+	if !a.IsZero() {
+		c.SetAddress(contact.AddressHome, a)
 	}
 
 	if v, ok := row["Individu.TelephoneDomicile"]; ok {
@@ -204,38 +211,42 @@ func extractIntranetLegalGardianContact(row parser.Row, index int) (*contact.Con
 		c.SetEmail(contact.EmailDedicatedSGDF, v)
 	}
 
+	var a contact.Address
 	if v, ok := row[fmt.Sprintf("RepresentantLegal%d.Adresse.Ligne1", index)]; ok {
-		c.Address = address.FormatLine(v)
+		a.Street = address.FormatLine(v)
 	}
 
 	if v, ok := row[fmt.Sprintf("RepresentantLegal%d.Adresse.Ligne2", index)]; ok {
-		if c.Address != "" && v != "" {
-			c.Address += "\n"
+		if a.Street != "" && v != "" {
+			a.Street += "\n"
 		}
-		if v != c.Address && v != "" {
-			c.Address += address.FormatLine(v)
+		if v != a.Street && v != "" {
+			a.Street += address.FormatLine(v)
 		}
 	}
 
 	if v, ok := row[fmt.Sprintf("RepresentantLegal%d.Adresse.Ligne3", index)]; ok {
-		if c.Address != "" && v != "" {
-			c.Address += "\n"
+		if a.Street != "" && v != "" {
+			a.Street += "\n"
 		}
-		if v != c.Address && v != "" {
-			c.Address += address.FormatLine(v)
+		if v != a.Street && v != "" {
+			a.Street += address.FormatLine(v)
 		}
 	}
 
 	if v, ok := row[fmt.Sprintf("RepresentantLegal%d.Adresse.CodePostal", index)]; ok {
-		c.ZipCode = v
+		a.ZipCode = v
 	}
 
 	if v, ok := row[fmt.Sprintf("RepresentantLegal%d.Adresse.Municipalite", index)]; ok {
-		c.City = capitalizer.String(v)
+		a.City = capitalizer.String(v)
 	}
 
 	if v, ok := row[fmt.Sprintf("RepresentantLegal%d.Adresse.Pays", index)]; ok {
-		c.Country = capitalizer.String(v)
+		a.Country = capitalizer.String(v)
+	}
+	if !a.IsZero() {
+		c.SetAddress(contact.AddressHome, a)
 	}
 
 	if v, ok := row[fmt.Sprintf("RepresentantLegal%d.TelephoneDomicile", index)]; ok {
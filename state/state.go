@@ -0,0 +1,135 @@
+// Package state keeps the local sync state of totem between runs: the last
+// exported version of each contact, by contact key.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/tinque/totem/contact"
+)
+
+// version is the version of the state file format.
+const version = 1
+
+// State is the last exported version of each contact, by key (see
+// contact.Contact.Key). Contacts without a key are not recorded.
+type State struct {
+	Version   int                        `json:"version"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+	Contacts  map[string]contact.Contact `json:"contacts"`
+}
+
+// New returns an empty state.
+func New() *State {
+	return &State{Version: version, Contacts: make(map[string]contact.Contact)}
+}
+
+// Read reads a JSON state.
+func Read(r io.Reader) (*State, error) {
+	s := New()
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, fmt.Errorf("error decoding state: %v", err)
+	}
+	if s.Version != version {
+		return nil, fmt.Errorf("unsupported state version %d", s.Version)
+	}
+	if s.Contacts == nil {
+		s.Contacts = make(map[string]contact.Contact)
+	}
+	return s, nil
+}
+
+// Load reads the state file at path, or returns an empty state if there is
+// none yet.
+func Load(path string) (*State, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Write writes the state as indented JSON.
+func (s *State) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Save writes the state file at path. The file is replaced at once, so that
+// an interrupted run leaves the previous state intact.
+func (s *State) Save(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := s.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get returns the last exported version of the contact with a key.
+func (s *State) Get(key string) (contact.Contact, bool) {
+	c, ok := s.Contacts[key]
+	return c, ok
+}
+
+// Previous returns the last exported contacts, ordered by key.
+func (s *State) Previous() []contact.Contact {
+	keys := make([]string, 0, len(s.Contacts))
+	for key := range s.Contacts {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	contacts := make([]contact.Contact, 0, len(keys))
+	for _, key := range keys {
+		contacts = append(contacts, s.Contacts[key])
+	}
+	return contacts
+}
+
+// Changes sorts the contacts of a run against the state: the contacts it
+// does not know, and the contacts whose values differ from their last
+// exported version. Contacts without a key are new.
+func (s *State) Changes(contacts []contact.Contact) (added, changed []contact.Contact) {
+	for _, c := range contacts {
+		previous, ok := s.Get(c.Key())
+		switch {
+		case !ok:
+			added = append(added, c)
+		case !c.Equal(&previous):
+			changed = append(changed, c)
+		}
+	}
+	return added, changed
+}
+
+// Record replaces the state with the contacts exported at a time.
+func (s *State) Record(contacts []contact.Contact, at time.Time) {
+	s.Version = version
+	s.UpdatedAt = at
+	s.Contacts = make(map[string]contact.Contact, len(contacts))
+	for _, c := range contacts {
+		if key := c.Key(); key != "" {
+			s.Contacts[key] = c
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package state
+
+import (
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tinque/totem/contact"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing file error = %v", err)
+	}
+	if len(s.Contacts) != 0 {
+		t.Errorf("Load() of a missing file = %d contacts, want 0", len(s.Contacts))
+	}
+
+	at := time.Date(2025, 9, 16, 10, 0, 0, 0, time.UTC)
+	birthday := time.Date(2010, 5, 3, 0, 0, 0, 0, time.UTC)
+	contacts := []contact.Contact{
+		{
+			MemberCode: "123",
+			FirstName:  "Louis",
+			Birthday:   &birthday,
+			Phones:     map[contact.PhoneType]string{contact.PhoneMobile1: "0612345678"},
+			Labels:     []contact.Label{contact.LabelAdherent},
+			Provenance: map[contact.Field]contact.Source{contact.FieldFirstName: {Name: "intranet", Row: 2, Time: at}},
+			Stamps:     map[contact.Field]contact.Stamp{contact.FieldFirstName: {Time: at, Hash: "a1b2c3d4"}},
+		},
+		{FirstName: "Marie", Emails: map[contact.EmailType]string{contact.EmailPersonal: "Marie@Example.com"}},
+		{FirstName: "Nobody"},
+	}
+	s.Record(contacts, at)
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !got.UpdatedAt.Equal(at) {
+		t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, at)
+	}
+	want := []contact.Contact{contacts[0], contacts[1]}
+	if !reflect.DeepEqual(got.Previous(), want) {
+		t.Errorf("Previous() = %+v, want %+v", got.Previous(), want)
+	}
+	if _, ok := got.Get("marie@example.com"); !ok {
+		t.Error("Get() by email not found")
+	}
+}
+
+func TestRead(t *testing.T) {
+	if _, err := Read(strings.NewReader(`{"version": 2, "contacts": {}}`)); err == nil {
+		t.Error("Read() of an unknown version, want an error")
+	}
+	if _, err := Read(strings.NewReader(`{"version": `)); err == nil {
+		t.Error("Read() of invalid JSON, want an error")
+	}
+	s, err := Read(strings.NewReader(`{"version": 1}`))
+	if err != nil || s.Contacts == nil {
+		t.Errorf("Read() = %+v, %v, want an empty state", s, err)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	s := New()
+	s.Record([]contact.Contact{
+		{MemberCode: "1", FirstName: "Louis", Addresses: map[contact.AddressType]contact.Address{contact.AddressHome: {City: "Paris"}}},
+		{MemberCode: "2", FirstName: "Marie", Addresses: map[contact.AddressType]contact.Address{contact.AddressHome: {City: "Lyon"}}},
+	}, time.Now())
+
+	added, changed := s.Changes([]contact.Contact{
+		{MemberCode: "1", FirstName: "Louis", Addresses: map[contact.AddressType]contact.Address{contact.AddressHome: {City: "PARIS"}}},
+		{MemberCode: "2", FirstName: "Marie", Addresses: map[contact.AddressType]contact.Address{contact.AddressHome: {City: "Lille"}}},
+		{MemberCode: "3", FirstName: "Paul"},
+		{FirstName: "Nobody"},
+	})
+	if len(added) != 2 || added[0].MemberCode != "3" || added[1].FirstName != "Nobody" {
+		t.Errorf("Changes() added = %+v, want 3 and Nobody", added)
+	}
+	if len(changed) != 1 || changed[0].MemberCode != "2" {
+		t.Errorf("Changes() changed = %+v, want 2", changed)
+	}
+}
+
+func TestRunsWithoutGmail(t *testing.T) {
+	// Each run reads the intranet contacts without IDs, the state carries them
+	intranet := func() []contact.Contact {
+		return []contact.Contact{
+			{MemberCode: "123", FirstName: "Louis", LastName: "Martin"},
+			{FirstName: "Marie", LastName: "Martin", Emails: map[contact.EmailType]string{contact.EmailPersonal: "marie@example.com"}},
+		}
+	}
+	run := func(s *State) (added, changed []contact.Contact) {
+		d := &contact.Deduplicator{Previous: s.Previous()}
+		contacts := d.Deduplicate(intranet())
+		for i := range contacts {
+			contacts[i].AssignID()
+		}
+		added, changed = s.Changes(contacts)
+		s.Record(contacts, time.Now())
+		return added, changed
+	}
+
+	s := New()
+	if added, _ := run(s); len(added) != 2 {
+		t.Fatalf("first run added = %d contacts, want 2", len(added))
+	}
+	ids := slices.Sorted(maps.Keys(s.Contacts))
+	added, changed := run(s)
+	if len(added) != 0 || len(changed) != 0 {
+		t.Errorf("second run added, changed = %+v, %+v, want none", added, changed)
+	}
+	if got := slices.Sorted(maps.Keys(s.Contacts)); !slices.Equal(got, ids) {
+		t.Errorf("second run IDs = %v, want %v", got, ids)
+	}
+}